@@ -1,5 +1,10 @@
 package data
 
+import (
+	"math"
+	"time"
+)
+
 /*
 	ASSET
 */
@@ -7,6 +12,8 @@ package data
 type Asset interface {
 	Empty() bool
 	Dir() Direction
+	Qty() int
+	Cost() float64
 }
 
 type Assets []Asset
@@ -18,16 +25,30 @@ type Assets []Asset
 type Stocks []Stock
 
 type Stock struct {
-	Ticker string
-	Price  float64
-	Shares int
+	Ticker string  `json:"ticker" yaml:"ticker"`
+	Price  float64 `json:"price" yaml:"price"`
+	Shares int     `json:"shares" yaml:"shares"`
+	Side   Side    `json:"side" yaml:"side"`
 }
 
 func (s Stock) Dir() Direction {
-	if s.Price < 0 {
-		return S
-	}
-	return L
+	return s.Side.dir(s.Price)
+}
+
+func (s Stock) Qty() int {
+	return s.Shares
+}
+
+// Signed cost of the position: positive when paid, negative when received.
+func (s Stock) Cost() float64 {
+	return signed(s.Price, s.Dir()) * float64(s.Shares)
+}
+
+// Converts a stock in the signed-price form into one with an explicit Side and non-negative Price.
+func (s Stock) Explicit() Stock {
+	s.Side = s.Dir().Side()
+	s.Price = math.Abs(s.Price)
+	return s
 }
 
 func (s Stock) Empty() bool {
@@ -47,13 +68,22 @@ func (ss Stocks) Swap(i, j int) {
 	ss[i], ss[j] = ss[j], ss[i]
 }
 
+// Net price per share or contract of the stocks: positive when paid, negative when received, whichever
+// way each leg records its side. Quantities and multipliers are left out; see Cost.
 func (ss *Stocks) Price() (price float64) {
 	for _, st := range *ss {
-		price += st.Price
+		price += signed(st.Price, st.Dir())
 	}
 	return price
 }
 
+func (ss *Stocks) Cost() (cost float64) {
+	for _, st := range *ss {
+		cost += st.Cost()
+	}
+	return cost
+}
+
 func (ss *Stocks) Shares() (shares int) {
 	for _, st := range *ss {
 		shares += st.Shares
@@ -65,9 +95,13 @@ func (ss *Stocks) Shares() (shares int) {
 	PUTS
 */
 type Put struct {
-	Underlying Stock
-	Price      float64
-	Strike     float64
+	Underlying Stock     `json:"underlying" yaml:"underlying"`
+	Price      float64   `json:"price" yaml:"price"`
+	Strike     float64   `json:"strike" yaml:"strike"`
+	Expiration time.Time `json:"expiration" yaml:"expiration"`
+	Side       Side      `json:"side" yaml:"side"`
+	Quantity   int       `json:"quantity" yaml:"quantity"`
+	Multiplier float64   `json:"multiplier" yaml:"multiplier"`
 }
 
 type Puts []Put
@@ -77,10 +111,40 @@ func (p Put) Empty() bool {
 }
 
 func (p Put) Dir() Direction {
-	if p.Price < 0 {
-		return S
+	return p.Side.dir(p.Price)
+}
+
+// Number of contracts. Legs without a Quantity count as a single contract.
+func (p Put) Qty() int {
+	if p.Quantity == 0 {
+		return 1
 	}
-	return L
+	return p.Quantity
+}
+
+// Shares controlled by one contract. Defaults to ContractMultiplier.
+func (p Put) Mult() float64 {
+	if p.Multiplier == 0 {
+		return ContractMultiplier
+	}
+	return p.Multiplier
+}
+
+// Signed cost of the position: positive when paid, negative when received.
+func (p Put) Cost() float64 {
+	return signed(p.Price, p.Dir()) * float64(p.Qty()) * p.Mult()
+}
+
+// Converts a put in the signed-price form into one with an explicit Side and non-negative Price.
+func (p Put) Explicit() Put {
+	p.Side = p.Dir().Side()
+	p.Price = math.Abs(p.Price)
+	return p
+}
+
+// Number of calendar days from now until the put expires. Negative once expired.
+func (p Put) DaysToExpiry(now time.Time) int {
+	return daysBetween(now, p.Expiration)
 }
 
 func (ps Puts) Len() int {
@@ -90,8 +154,10 @@ func (ps Puts) Len() int {
 func (ps Puts) Less(i, j int) bool {
 	if ps[i].Underlying.Ticker != ps[j].Underlying.Ticker {
 		return ps[i].Underlying.Ticker < ps[j].Underlying.Ticker
-	} else {
+	} else if ps[i].Strike != ps[j].Strike {
 		return ps[i].Strike < ps[j].Strike
+	} else {
+		return ps[i].Expiration.Before(ps[j].Expiration)
 	}
 }
 
@@ -99,20 +165,33 @@ func (ps Puts) Swap(i, j int) {
 	ps[i], ps[j] = ps[j], ps[i]
 }
 
+// Net price per share or contract of the puts: positive when paid, negative when received, whichever
+// way each leg records its side. Quantities and multipliers are left out; see Cost.
 func (ps Puts) Price() (price float64) {
 	for _, p := range ps {
-		price += p.Price
+		price += signed(p.Price, p.Dir())
 	}
 	return price
 }
 
+func (ps Puts) Cost() (cost float64) {
+	for _, p := range ps {
+		cost += p.Cost()
+	}
+	return cost
+}
+
 /*
 	CALL
 */
 type Call struct {
-	Underlying Stock
-	Price      float64
-	Strike     float64
+	Underlying Stock     `json:"underlying" yaml:"underlying"`
+	Price      float64   `json:"price" yaml:"price"`
+	Strike     float64   `json:"strike" yaml:"strike"`
+	Expiration time.Time `json:"expiration" yaml:"expiration"`
+	Side       Side      `json:"side" yaml:"side"`
+	Quantity   int       `json:"quantity" yaml:"quantity"`
+	Multiplier float64   `json:"multiplier" yaml:"multiplier"`
 }
 
 type Calls []Call
@@ -122,10 +201,40 @@ func (c Call) Empty() bool {
 }
 
 func (c Call) Dir() Direction {
-	if c.Price < 0 {
-		return S
+	return c.Side.dir(c.Price)
+}
+
+// Number of contracts. Legs without a Quantity count as a single contract.
+func (c Call) Qty() int {
+	if c.Quantity == 0 {
+		return 1
 	}
-	return L
+	return c.Quantity
+}
+
+// Shares controlled by one contract. Defaults to ContractMultiplier.
+func (c Call) Mult() float64 {
+	if c.Multiplier == 0 {
+		return ContractMultiplier
+	}
+	return c.Multiplier
+}
+
+// Signed cost of the position: positive when paid, negative when received.
+func (c Call) Cost() float64 {
+	return signed(c.Price, c.Dir()) * float64(c.Qty()) * c.Mult()
+}
+
+// Converts a call in the signed-price form into one with an explicit Side and non-negative Price.
+func (c Call) Explicit() Call {
+	c.Side = c.Dir().Side()
+	c.Price = math.Abs(c.Price)
+	return c
+}
+
+// Number of calendar days from now until the call expires. Negative once expired.
+func (c Call) DaysToExpiry(now time.Time) int {
+	return daysBetween(now, c.Expiration)
 }
 
 func (cs Calls) Len() int {
@@ -135,8 +244,10 @@ func (cs Calls) Len() int {
 func (cs Calls) Less(i, j int) bool {
 	if cs[i].Underlying.Ticker != cs[j].Underlying.Ticker {
 		return cs[i].Underlying.Ticker < cs[j].Underlying.Ticker
-	} else {
+	} else if cs[i].Strike != cs[j].Strike {
 		return cs[i].Strike < cs[j].Strike
+	} else {
+		return cs[i].Expiration.Before(cs[j].Expiration)
 	}
 }
 
@@ -144,13 +255,82 @@ func (cs Calls) Swap(i, j int) {
 	cs[i], cs[j] = cs[j], cs[i]
 }
 
+// Net price per share or contract of the calls: positive when paid, negative when received, whichever
+// way each leg records its side. Quantities and multipliers are left out; see Cost.
 func (cs Calls) Price() (price float64) {
 	for _, c := range cs {
-		price += c.Price
+		price += signed(c.Price, c.Dir())
 	}
 	return price
 }
 
+func (cs Calls) Cost() (cost float64) {
+	for _, c := range cs {
+		cost += c.Cost()
+	}
+	return cost
+}
+
+/*
+	SIDE
+*/
+
+// Shares controlled by a standard equity option contract.
+const ContractMultiplier float64 = 100
+
+// Explicit side of a leg. Legs with an Unset side infer their direction from the sign of their Price.
+type Side int
+
+const (
+	Unset Side = iota
+	Buy   Side = iota
+	Sell  Side = iota
+)
+
+func (s Side) String() string {
+	return []string{"Unset", "Buy", "Sell"}[s]
+}
+
+func (s Side) dir(price float64) Direction {
+	switch s {
+	case Buy:
+		return L
+	case Sell:
+		return S
+	}
+	if price < 0 {
+		return S
+	}
+	return L
+}
+
+// Applies the sign of a direction to a price.
+func signed(price float64, d Direction) float64 {
+	if d == S {
+		return -math.Abs(price)
+	}
+	return math.Abs(price)
+}
+
+/*
+	EXPIRATION
+*/
+
+// Expiration of options expiring on a date, kept at 16:00 UTC like every other expiration in the package so
+// that legs parsed from different sources compare equal.
+func ExpiresOn(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 16, 0, 0, 0, time.UTC)
+}
+
+// Whole calendar days between the dates of from and to, ignoring time of day.
+func daysBetween(from, to time.Time) int {
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.Date()
+	f := time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)
+	t := time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)
+	return int(t.Sub(f).Hours() / 24)
+}
+
 /*
 	DIRECTION
 */
@@ -165,3 +345,14 @@ const (
 func (d Direction) String() string {
 	return []string{"Long", "Short", "None"}[d]
 }
+
+// Side that opens a position in the direction. None has no side.
+func (d Direction) Side() Side {
+	switch d {
+	case L:
+		return Buy
+	case S:
+		return Sell
+	}
+	return Unset
+}
@@ -28,6 +28,22 @@ func TestStock(t *testing.T) {
 		},
 		GenStock(GenTickers())))
 
+	ps.Property("Stock.Explicit keeps direction and cost", prop.ForAll(
+		func(s Stock) bool {
+			e := s.Explicit()
+			return e.Price >= 0 && e.Dir() == s.Dir() && e.Cost() == s.Cost()
+		},
+		GenStock(GenTickers())))
+
+	ps.Property("Stock.Dir follows an explicit Side", prop.ForAll(
+		func(s Stock) bool {
+			s.Side = Sell
+			short := s.Dir() == S
+			s.Side = Buy
+			return short && s.Dir() == L
+		},
+		GenStock(GenTickers())))
+
 	ps.Property("Stock.Empty == true when Stock object empty", prop.ForAll(
 		func(s Stock) bool {
 			if s.Ticker == "" {
@@ -54,11 +70,11 @@ func TestStock(t *testing.T) {
 			return true
 		}))
 
-	ps.Property("Stocks.Price == sum of all prices", arbs.ForAll(
+	ps.Property("Stocks.Price == sum of all signed prices", arbs.ForAll(
 		func(ss Stocks) bool {
 			sumP := 0.0
 			for _, s := range ss {
-				sumP += s.Price
+				sumP += signed(s.Price, s.Dir())
 			}
 			eSumP := ss.Price()
 			return sumP == eSumP
@@ -95,6 +111,30 @@ func TestPut(t *testing.T) {
 		},
 		GenPut(GenTickers())))
 
+	ps.Property("Put.DaysToExpiry counts calendar days until expiration", prop.ForAll(
+		func(p Put, n int) bool {
+			now := p.Expiration.AddDate(0, 0, -n)
+			return p.DaysToExpiry(now) == n && p.DaysToExpiry(p.Expiration) == 0
+		},
+		GenPutWithExpiration(GenTickers(), GenExpirations()),
+		gen.IntRange(-MaxExpiryDays, MaxExpiryDays)))
+
+	ps.Property("Put.Explicit keeps direction and cost", prop.ForAll(
+		func(p Put) bool {
+			e := p.Explicit()
+			return e.Price >= 0 && e.Dir() == p.Dir() && e.Cost() == p.Cost()
+		},
+		GenPut(GenTickers())))
+
+	ps.Property("Put.Dir follows an explicit Side", prop.ForAll(
+		func(p Put) bool {
+			p.Side = Sell
+			short := p.Dir() == S
+			p.Side = Buy
+			return short && p.Dir() == L
+		},
+		GenPut(GenTickers())))
+
 	ps.Property("Put.Empty == true when Put object empty", prop.ForAll(
 		func(p Put) bool {
 			if p.Underlying.Ticker == "" {
@@ -138,11 +178,20 @@ func TestPut(t *testing.T) {
 			return true
 		}, gen.SliceOf(GenPut(GenTicker()))))
 
-	ps.Property("Puts.Price == sum of all prices", arbs.ForAll(
+	ps.Property("Puts.Price is unchanged by explicit sides", prop.ForAll(
+		func(xs Puts) bool {
+			ex := make(Puts, len(xs))
+			for i, p := range xs {
+				ex[i] = p.Explicit()
+			}
+			return ex.Price() == xs.Price()
+		}, gen.SliceOf(GenPut(GenTicker()))))
+
+	ps.Property("Puts.Price == sum of all signed prices", arbs.ForAll(
 		func(ps Puts) bool {
 			sum := 0.0
 			for _, p := range ps {
-				sum += p.Price
+				sum += signed(p.Price, p.Dir())
 			}
 			return sum == ps.Price()
 		}))
@@ -168,6 +217,30 @@ func TestCall(t *testing.T) {
 		},
 		GenCall(GenTickers())))
 
+	ps.Property("Call.DaysToExpiry counts calendar days until expiration", prop.ForAll(
+		func(c Call, n int) bool {
+			now := c.Expiration.AddDate(0, 0, -n)
+			return c.DaysToExpiry(now) == n && c.DaysToExpiry(c.Expiration) == 0
+		},
+		GenCallWithExpiration(GenTickers(), GenExpirations()),
+		gen.IntRange(-MaxExpiryDays, MaxExpiryDays)))
+
+	ps.Property("Call.Explicit keeps direction and cost", prop.ForAll(
+		func(c Call) bool {
+			e := c.Explicit()
+			return e.Price >= 0 && e.Dir() == c.Dir() && e.Cost() == c.Cost()
+		},
+		GenCall(GenTickers())))
+
+	ps.Property("Call.Dir follows an explicit Side", prop.ForAll(
+		func(c Call) bool {
+			c.Side = Sell
+			short := c.Dir() == S
+			c.Side = Buy
+			return short && c.Dir() == L
+		},
+		GenCall(GenTickers())))
+
 	ps.Property("Call.Empty == true when Call object empty", prop.ForAll(
 		func(c Call) bool {
 			if c.Underlying.Ticker == "" {
@@ -211,11 +284,20 @@ func TestCall(t *testing.T) {
 			return true
 		}, gen.SliceOf(GenCall(GenTicker()))))
 
-	ps.Property("Calls.Price == sum of all prices", arbs.ForAll(
+	ps.Property("Calls.Price is unchanged by explicit sides", prop.ForAll(
+		func(xs Calls) bool {
+			ex := make(Calls, len(xs))
+			for i, c := range xs {
+				ex[i] = c.Explicit()
+			}
+			return ex.Price() == xs.Price()
+		}, gen.SliceOf(GenCall(GenTicker()))))
+
+	ps.Property("Calls.Price == sum of all signed prices", arbs.ForAll(
 		func(cs Calls) bool {
 			sum := 0.0
 			for _, c := range cs {
-				sum += c.Price
+				sum += signed(c.Price, c.Dir())
 			}
 			return sum == cs.Price()
 		}))
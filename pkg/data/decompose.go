@@ -0,0 +1,299 @@
+package data
+
+import (
+	"sort"
+)
+
+const (
+	// Most legs tried together as a single strategy when decomposing a position.
+	MaxStrategyLegs = 4
+	// Number of candidate groupings Decompose examines before settling for the best found so far.
+	DefaultDecomposeSteps = 20000
+	// Most legs searched together. Larger positions are decomposed in chunks of sorted legs.
+	maxSearchLegs = 64
+)
+
+// Breaks the legs of a single ticker into the combination of named strategies that covers every leg with the
+// fewest legs left over in a Custom remainder. Among equally good combinations it prefers, in order, fewer
+// strategies, fewer NakedStock strategies (so stock is paired with options where possible) and higher
+// priority Types, with remaining ties resolved by the order of the sorted legs. At most maxSteps groupings
+// are examined, which bounds the search time for large positions; once exhausted the best combination found
+// so far is returned. A position recognized as a whole is returned unchanged.
+func Decompose(ss Stocks, ps Puts, cs Calls, maxSteps int) ([]Strategy, error) {
+	var as []Asset
+	for _, st := range ss {
+		as = append(as, st)
+	}
+	for _, p := range ps {
+		as = append(as, p)
+	}
+	for _, c := range cs {
+		as = append(as, c)
+	}
+	return decompose(as, maxSteps)
+}
+
+// Breaks the legs of the strategy into named strategies. See Decompose.
+func (s *Strategy) Decompose(maxSteps int) ([]Strategy, error) {
+	ps := append(append(Puts(nil), s.Lp...), s.Sp...)
+	cs := append(append(Calls(nil), s.Lc...), s.Sc...)
+	return Decompose(append(Stocks(nil), s.Stocks...), ps, cs, maxSteps)
+}
+
+func decompose(as []Asset, maxSteps int) ([]Strategy, error) {
+	whole, e := newStrategy(as)
+	if e != nil || whole.Type != Custom {
+		return []Strategy{whole}, e
+	}
+
+	ss, ps, cs := split(as)
+	sort.Sort(ss)
+	sort.Sort(ps)
+	sort.Sort(cs)
+	var sorted []Asset
+	for _, st := range ss {
+		sorted = append(sorted, st)
+	}
+	for _, p := range ps {
+		sorted = append(sorted, p)
+	}
+	for _, c := range cs {
+		sorted = append(sorted, c)
+	}
+
+	// Groups of legs are tracked as bit masks.
+	if len(sorted) > maxSearchLegs {
+		head, e := decompose(sorted[:maxSearchLegs], maxSteps)
+		if e != nil {
+			return nil, e
+		}
+		tail, e := decompose(sorted[maxSearchLegs:], maxSteps)
+		return append(head, tail...), e
+	}
+
+	x := search{legs: sorted, max: maxSteps, cache: make(map[uint64]*Strategy)}
+	x.greedy()
+	x.run(0, nil, 0)
+	return x.best, x.err
+}
+
+// Quality of a decomposition. Lower is better, compared field by field.
+type score struct {
+	custom     int
+	strategies int
+	naked      int
+	priority   int
+}
+
+func (a score) less(b score) bool {
+	if a.custom != b.custom {
+		return a.custom < b.custom
+	}
+	if a.strategies != b.strategies {
+		return a.strategies < b.strategies
+	}
+	if a.naked != b.naked {
+		return a.naked < b.naked
+	}
+	return a.priority < b.priority
+}
+
+// Depth first search over partitions of the legs.
+type search struct {
+	legs      []Asset
+	steps     int
+	max       int
+	cache     map[uint64]*Strategy
+	best      []Strategy
+	bestScore score
+	err       error
+}
+
+// Returns the strategy formed by the legs in mask, or nil when they are not recognized as a named Type.
+func (x *search) recognize(mask uint64) *Strategy {
+	if s, ok := x.cache[mask]; ok {
+		return s
+	}
+	x.steps++
+
+	s, e := newStrategy(x.group(mask))
+	if e != nil || s.Type == Custom || s.Type == Empty {
+		x.cache[mask] = nil
+		return nil
+	}
+	x.cache[mask] = &s
+	return &s
+}
+
+func (x *search) group(mask uint64) []Asset {
+	var as []Asset
+	for i, a := range x.legs {
+		if mask&(1<<uint(i)) != 0 {
+			as = append(as, a)
+		}
+	}
+	return as
+}
+
+// Indices of the legs not in mask.
+func (x *search) free(mask uint64) []int {
+	var idx []int
+	for i := range x.legs {
+		if mask&(1<<uint(i)) == 0 {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Seeds the search with a greedy decomposition: the largest recognized group of legs is taken first,
+// preferring groups that cover stock and then higher priority Types, until every leg is assigned.
+// Only single legs are tried once the step budget is spent.
+func (x *search) greedy() {
+	var groups []Strategy
+	var assigned uint64
+	for {
+		free := x.free(assigned)
+		if len(free) == 0 {
+			break
+		}
+
+		var best *Strategy
+		var bestMask uint64
+		for k := min(MaxStrategyLegs, len(free)); k > 0 && best == nil; k-- {
+			combinations(len(free), k, func(idx []int) {
+				if k > 1 && x.steps >= x.max {
+					return
+				}
+				var mask uint64
+				for _, j := range idx {
+					mask |= 1 << uint(free[j])
+				}
+				if s := x.recognize(mask); s != nil && (best == nil || better(*s, *best)) {
+					best, bestMask = s, mask
+				}
+			})
+		}
+
+		// Nothing left is recognized on its own.
+		if best == nil {
+			break
+		}
+		groups = append(groups, *best)
+		assigned |= bestMask
+	}
+
+	// Worse than any decomposition so the greedy one is always recorded.
+	x.bestScore = score{custom: len(x.legs) + 1}
+	x.finish(groups, ^assigned&(1<<uint(len(x.legs))-1))
+}
+
+// Assigns the lowest unassigned leg to every recognized group it can join, or to the Custom remainder,
+// and recurses until every leg is assigned.
+func (x *search) run(assigned uint64, groups []Strategy, custom uint64) {
+	if x.steps >= x.max {
+		return
+	}
+
+	free := x.free(assigned)
+	if len(free) == 0 {
+		x.finish(groups, custom)
+		return
+	}
+	first, rest := free[0], free[1:]
+
+	// Prune when even perfectly packing the remaining legs cannot beat the best found.
+	bound := score{custom: bits(custom), strategies: len(groups) + (len(free)+MaxStrategyLegs-1)/MaxStrategyLegs}
+	if custom != 0 {
+		bound.strategies++
+	}
+	if x.bestScore.custom < bound.custom ||
+		(x.bestScore.custom == bound.custom && x.bestScore.strategies < bound.strategies) {
+		return
+	}
+
+	for k := min(MaxStrategyLegs, len(free)) - 1; k >= 0; k-- {
+		combinations(len(rest), k, func(idx []int) {
+			mask := uint64(1) << uint(first)
+			for _, j := range idx {
+				mask |= 1 << uint(rest[j])
+			}
+			if s := x.recognize(mask); s != nil {
+				x.run(assigned|mask, append(groups[:len(groups):len(groups)], *s), custom)
+			}
+		})
+	}
+
+	bit := uint64(1) << uint(first)
+	x.run(assigned|bit, groups, custom|bit)
+}
+
+// Records a complete decomposition, with the legs in custom as one Custom remainder, if it beats the best
+// found so far.
+func (x *search) finish(groups []Strategy, custom uint64) {
+	sc := score{custom: bits(custom), strategies: len(groups)}
+	if custom != 0 {
+		sc.strategies++
+	}
+	for _, s := range groups {
+		sc.priority += priorityOf(s.Type)
+		if s.Type == NakedStock {
+			sc.naked++
+		}
+	}
+	if !sc.less(x.bestScore) {
+		return
+	}
+
+	best := append([]Strategy(nil), groups...)
+	if custom != 0 {
+		s, e := newStrategy(x.group(custom))
+		if e != nil {
+			x.err = e
+			return
+		}
+		best = append(best, s)
+	}
+	x.best, x.bestScore = best, sc
+}
+
+// Prefers strategies holding stock, then higher priority Types.
+func better(a, b Strategy) bool {
+	if (len(a.Stocks) > 0) != (len(b.Stocks) > 0) {
+		return len(a.Stocks) > 0
+	}
+	return priorityOf(a.Type) < priorityOf(b.Type)
+}
+
+func priorityOf(t Type) int {
+	for _, c := range registry() {
+		if c.kind == t {
+			return c.priority
+		}
+	}
+	return customPriority
+}
+
+func bits(m uint64) (n int) {
+	for ; m != 0; m &= m - 1 {
+		n++
+	}
+	return n
+}
+
+// Calls f with every ascending selection of k indices out of n.
+func combinations(n, k int, f func([]int)) {
+	idx := make([]int, k)
+	var rec func(start, depth int)
+	rec = func(start, depth int) {
+		if depth == k {
+			f(idx)
+			return
+		}
+		for i := start; i <= n-(k-depth); i++ {
+			idx[depth] = i
+			rec(i+1, depth+1)
+		}
+	}
+	rec(0, 0)
+}
@@ -0,0 +1,82 @@
+package data
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"os"
+	"testing"
+)
+
+func TestDecompose(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	decompose := func(ss Stocks, ps Puts, cs Calls) ([]Strategy, error) {
+		return Decompose(ss, ps, cs, DefaultDecomposeSteps)
+	}
+
+	count := func(ss []Strategy) (n int) {
+		for _, s := range ss {
+			n += len(s.Stocks) + s.CountOptions()
+		}
+		return n
+	}
+
+	ps.Property("Decompose covers every leg without Custom remainders", prop.ForAll(
+		func(a, b Strategy) bool {
+			ss, e := decompose(legs(a, b))
+			if e != nil || count(ss) != len(a.Stocks)+a.CountOptions()+len(b.Stocks)+b.CountOptions() {
+				return false
+			}
+			for _, s := range ss {
+				if s.Type == Custom {
+					return false
+				}
+			}
+			return true
+		},
+		GenStrategy(gen.Const("AAA")).SuchThat(func(s Strategy) bool { return !s.empty() }),
+		GenStrategy(gen.Const("AAA")).SuchThat(func(s Strategy) bool { return !s.empty() })))
+
+	ps.Property("Decompose finds both halves of a combined position", prop.ForAll(
+		func(a, b Strategy) bool {
+			ss, e := decompose(legs(a, b))
+			if e != nil || len(ss) != 2 {
+				return false
+			}
+			return (ss[0].Type == a.Type && ss[1].Type == b.Type) || (ss[0].Type == b.Type && ss[1].Type == a.Type)
+		},
+		GenShortIronCondorStrategy(gen.Const("AAA")),
+		GenShortCoveredCallStrategy(gen.Const("AAA"))))
+
+	ps.Property("Decompose does not depend on leg order", prop.ForAll(
+		func(a, b Strategy) bool {
+			ss, ps, cs := legs(a, b)
+			x, e1 := decompose(ss, ps, cs)
+			ss, ps, cs = legs(b, a)
+			y, e2 := decompose(ss, ps, cs)
+			if e1 != nil || e2 != nil || len(x) != len(y) {
+				return false
+			}
+			for i := range x {
+				if x[i].Type != y[i].Type || x[i].Dir != y[i].Dir {
+					return false
+				}
+			}
+			return true
+		},
+		GenStrategy(gen.Const("AAA")).SuchThat(func(s Strategy) bool { return !s.empty() }),
+		GenStrategy(gen.Const("AAA")).SuchThat(func(s Strategy) bool { return !s.empty() })))
+
+	ps.Property("Decompose stops within its step budget on large positions", prop.ForAll(
+		func(ss []Strategy) bool {
+			sts, ps, cs := legs(ss...)
+			d, e := Decompose(sts, ps, cs, 500)
+			return e == nil && count(d) == count(ss)
+		},
+		gen.SliceOfN(8, GenStrategy(gen.Const("AAA")))))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,133 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Version of the document written when a Strategy is encoded. Documents of any other version are rejected.
+const SchemaVersion = 1
+
+var (
+	ErrSchemaVersion = errors.New("unsupported strategy schema version")
+	ErrTypeMismatch  = errors.New("declared strategy type does not match its legs")
+	ErrUnknownName   = errors.New("unknown name")
+)
+
+// Strategy without its encoding methods, so a document can embed it.
+type strategy Strategy
+
+// Encoded form of a Strategy.
+type document struct {
+	Version  int `json:"version" yaml:"version"`
+	strategy `yaml:",inline"`
+}
+
+// Encodes the strategy as a versioned JSON document with its Type and Direction written as names.
+func (s Strategy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(document{SchemaVersion, strategy(s)})
+}
+
+// Decodes a JSON document written by MarshalJSON. The legs are classified again with NewStrategy and the
+// document is rejected unless they are of the declared Type and Direction.
+func (s *Strategy) UnmarshalJSON(b []byte) error {
+	var d document
+	if e := json.Unmarshal(b, &d); e != nil {
+		return e
+	}
+	return s.decode(d)
+}
+
+// Encodes the strategy as a versioned YAML document. See MarshalJSON.
+func (s Strategy) MarshalYAML() (interface{}, error) {
+	return document{SchemaVersion, strategy(s)}, nil
+}
+
+// Decodes a YAML document written by MarshalYAML. See UnmarshalJSON.
+func (s *Strategy) UnmarshalYAML(n *yaml.Node) error {
+	var d document
+	if e := n.Decode(&d); e != nil {
+		return e
+	}
+	return s.decode(d)
+}
+
+func (s *Strategy) decode(d document) error {
+	if d.Version != SchemaVersion {
+		return ErrSchemaVersion
+	}
+
+	ps := append(append(Puts{}, d.Lp...), d.Sp...)
+	cs := append(append(Calls{}, d.Sc...), d.Lc...)
+	st, e := NewStrategy(d.Stocks, ps, cs)
+	if e != nil {
+		return e
+	}
+	// NewStrategy leaves a strategy without legs unclassified.
+	if st.empty() {
+		st.Ticker = d.Ticker
+		st.Type, st.Dir = st.CheckKind()
+	}
+	if st.Ticker != d.Ticker || st.Type != d.Type || st.Dir != d.Dir {
+		return ErrTypeMismatch
+	}
+	*s = st
+	return nil
+}
+
+// Writes the name the Type prints as. Unregistered Types have no name.
+func (t Type) MarshalText() ([]byte, error) {
+	if t < 0 {
+		return nil, ErrUnknownName
+	}
+	n := t.String()
+	if k, ok := typeNamed(n); !ok || k != t {
+		return nil, ErrUnknownName
+	}
+	return []byte(n), nil
+}
+
+// Reads the name of a built-in or registered Type.
+func (t *Type) UnmarshalText(b []byte) error {
+	k, ok := typeNamed(string(b))
+	if !ok {
+		return ErrUnknownName
+	}
+	*t = k
+	return nil
+}
+
+func (d Direction) MarshalText() ([]byte, error) {
+	if d < L || d > None {
+		return nil, ErrUnknownName
+	}
+	return []byte(d.String()), nil
+}
+
+func (d *Direction) UnmarshalText(b []byte) error {
+	for k := L; k <= None; k++ {
+		if k.String() == string(b) {
+			*d = k
+			return nil
+		}
+	}
+	return ErrUnknownName
+}
+
+func (s Side) MarshalText() ([]byte, error) {
+	if s < Unset || s > Sell {
+		return nil, ErrUnknownName
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *Side) UnmarshalText(b []byte) error {
+	for k := Unset; k <= Sell; k++ {
+		if k.String() == string(b) {
+			*s = k
+			return nil
+		}
+	}
+	return ErrUnknownName
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"gopkg.in/yaml.v3"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The strategy NewStrategy builds from the legs of s.
+func classified(s Strategy) Strategy {
+	ps := append(append(Puts{}, s.Lp...), s.Sp...)
+	cs := append(append(Calls{}, s.Sc...), s.Lc...)
+	st, _ := NewStrategy(s.Stocks, ps, cs)
+	if st.empty() {
+		st.Type, st.Dir = st.CheckKind()
+	}
+	return st
+}
+
+func TestEncoding(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	t.Cleanup(restoreRegistry())
+	never, e := Register("EncodedLizard", 0, RecognizerFunc(func(*Strategy) (Direction, bool) { return None, false }))
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	ps.Property("Strategies survive a JSON round trip", prop.ForAll(
+		func(s Strategy) bool {
+			s = classified(s)
+			b, e := json.Marshal(s)
+			if e != nil {
+				return false
+			}
+			var d Strategy
+			return json.Unmarshal(b, &d) == nil && reflect.DeepEqual(s, d)
+		},
+		GenStrategy(GenTicker())))
+
+	ps.Property("Strategies survive a YAML round trip", prop.ForAll(
+		func(s Strategy) bool {
+			s = classified(s)
+			b, e := yaml.Marshal(s)
+			if e != nil {
+				return false
+			}
+			var d Strategy
+			return yaml.Unmarshal(b, &d) == nil && reflect.DeepEqual(s, d)
+		},
+		GenStrategy(GenTicker())))
+
+	ps.Property("Documents carry the schema version and the type and direction by name", prop.ForAll(
+		func(s Strategy) bool {
+			s = classified(s)
+			b, _ := json.Marshal(s)
+			var m map[string]interface{}
+			if json.Unmarshal(b, &m) != nil {
+				return false
+			}
+			return m["version"] == float64(SchemaVersion) && m["type"] == s.Type.String() && m["dir"] == s.Dir.String()
+		},
+		GenStrategy(GenTicker())))
+
+	ps.Property("Types, directions and sides decode from their names", prop.ForAll(
+		func(k Type, d Direction, n int) bool {
+			s := Side(n)
+			b, e := json.Marshal([]interface{}{k, d, s})
+			if e != nil || string(b) != `["`+k.String()+`","`+d.String()+`","`+s.String()+`"]` {
+				return false
+			}
+			var k2 Type
+			var d2 Direction
+			var s2 Side
+			e1 := k2.UnmarshalText([]byte(k.String()))
+			e2 := d2.UnmarshalText([]byte(d.String()))
+			e3 := s2.UnmarshalText([]byte(s.String()))
+			return e1 == nil && e2 == nil && e3 == nil && k2 == k && d2 == d && s2 == s
+		},
+		gen.OneGenOf(GenType(), gen.Const(never)),
+		GenDirection(),
+		gen.IntRange(int(Unset), int(Sell))))
+
+	ps.Property("Unknown names and unregistered types are rejected", prop.ForAll(
+		func(n int) bool {
+			var k Type
+			_, e := json.Marshal(never + Type(n))
+			return e != nil && k.UnmarshalText([]byte("Unregistered")) != nil
+		},
+		gen.IntRange(1, 100)))
+
+	ps.Property("A declared type the legs do not have is rejected", prop.ForAll(
+		func(s Strategy, k Type) bool {
+			s = classified(s)
+			if k == s.Type {
+				return true
+			}
+			s.Type = k
+			b, _ := json.Marshal(s)
+			y, _ := yaml.Marshal(s)
+			var d Strategy
+			return errors.Is(json.Unmarshal(b, &d), ErrTypeMismatch) && errors.Is(yaml.Unmarshal(y, &d), ErrTypeMismatch)
+		},
+		GenStrategy(GenTicker()),
+		GenType()))
+
+	ps.Property("Other schema versions are rejected", prop.ForAll(
+		func(s Strategy, v int) bool {
+			b, _ := json.Marshal(classified(s))
+			b = []byte(strings.Replace(string(b), `"version":1`, `"version":`+strconv.Itoa(v), 1))
+			var d Strategy
+			return v == SchemaVersion || errors.Is(json.Unmarshal(b, &d), ErrSchemaVersion)
+		},
+		GenStrategy(GenTicker()),
+		gen.IntRange(0, 10)))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
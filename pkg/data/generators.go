@@ -7,6 +7,7 @@ import (
 	"math"
 	"reflect"
 	"strconv"
+	"time"
 )
 
 const (
@@ -15,8 +16,12 @@ const (
 	MaxOptionPrice float64 = 10
 	MaxStockPrice  float64 = 1000
 	MaxShares      int     = 200
+	MaxExpiryDays  int     = 720
 )
 
+// Expiration shared by every option produced by GenPut and GenCall.
+var Expiration = ExpiresOn(2020, time.January, 17)
+
 func GenDirection() gopter.Gen {
 	return gen.IntRange(0, 2).Map(func(i int) Direction {
 		return Direction(i)
@@ -24,7 +29,7 @@ func GenDirection() gopter.Gen {
 }
 
 func GenType() gopter.Gen {
-	return gen.IntRange(0, 15).Map(func(i int) Type {
+	return gen.IntRange(0, len(typeNames)-1).Map(func(i int) Type {
 		return Type(i)
 	})
 }
@@ -48,6 +53,20 @@ func GenTicker() gopter.Gen {
 	return gen.Const(t)
 }
 
+// Generates expirations on the Fridays following Expiration.
+func GenExpirations() gopter.Gen {
+	return gen.IntRange(0, MaxExpiryDays/7).Map(func(w int) time.Time {
+		return Expiration.AddDate(0, 0, 7*w)
+	})
+}
+
+// Generates expirations on the Fridays strictly after e.
+func GenExpirationsAfter(e time.Time) gopter.Gen {
+	return gen.IntRange(1, MaxExpiryDays/7).Map(func(w int) time.Time {
+		return e.AddDate(0, 0, 7*w)
+	})
+}
+
 func GenStock(ticker gopter.Gen) gopter.Gen {
 	return gen.Struct(
 		reflect.TypeOf(Stock{}),
@@ -97,7 +116,18 @@ func GenPut(ticker gopter.Gen) gopter.Gen {
 		map[string]gopter.Gen{
 			"Underlying": GenStock100Shares(ticker),
 			"Price":      gen.Float64Range(-MaxOptionPrice, MaxOptionPrice),
-			"Strike":     gen.Float64Range(MinStrike, MaxStrike)})
+			"Strike":     gen.Float64Range(MinStrike, MaxStrike),
+			"Expiration": gen.Const(Expiration)})
+}
+
+func GenPutWithExpiration(ticker gopter.Gen, expiration gopter.Gen) gopter.Gen {
+	return GenPut(ticker).FlatMap(func(p interface{}) gopter.Gen {
+		return expiration.Map(func(e time.Time) Put {
+			p := p.(Put)
+			p.Expiration = e
+			return p
+		})
+	}, reflect.TypeOf(Put{}))
 }
 
 func GenShortPut(ticker gopter.Gen) gopter.Gen {
@@ -140,7 +170,18 @@ func GenCall(ticker gopter.Gen) gopter.Gen {
 		map[string]gopter.Gen{
 			"Underlying": GenStock100Shares(ticker),
 			"Price":      gen.Float64Range(-MaxOptionPrice, MaxOptionPrice),
-			"Strike":     gen.Float64Range(MinStrike, MaxStrike)})
+			"Strike":     gen.Float64Range(MinStrike, MaxStrike),
+			"Expiration": gen.Const(Expiration)})
+}
+
+func GenCallWithExpiration(ticker gopter.Gen, expiration gopter.Gen) gopter.Gen {
+	return GenCall(ticker).FlatMap(func(c interface{}) gopter.Gen {
+		return expiration.Map(func(e time.Time) Call {
+			c := c.(Call)
+			c.Expiration = e
+			return c
+		})
+	}, reflect.TypeOf(Call{}))
 }
 
 func GenShortCall(ticker gopter.Gen) gopter.Gen {
@@ -376,8 +417,8 @@ func GenShortIronCondorStrategy(ticker gopter.Gen) gopter.Gen {
 		sc := s.Sc[0]
 
 		// Swap lp <-> sp && lc <-sc
-		sp.Price, lp.Price = -lp.Price, -sp.Price
-		sc.Price, sc.Price = -sc.Price, -sc.Price
+		sp.Price, lp.Price = -sp.Price, -lp.Price
+		sc.Price, lc.Price = -sc.Price, -lc.Price
 
 		return Strategy{
 			Ticker: s.Ticker,
@@ -418,8 +459,8 @@ func GenShortIronButterflyStrategy(ticker gopter.Gen) gopter.Gen {
 		sc := s.Sc[0]
 
 		// Swap lp <-> sp && lc <-sc
-		sp.Price, lp.Price = -lp.Price, -sp.Price
-		sc.Price, sc.Price = -sc.Price, -sc.Price
+		sp.Price, lp.Price = -sp.Price, -lp.Price
+		sc.Price, lc.Price = -sc.Price, -lc.Price
 
 		return Strategy{
 			Ticker: s.Ticker,
@@ -441,7 +482,7 @@ func GenLongCallButterflyStrategy(ticker gopter.Gen) gopter.Gen {
 			return lcu.Map(func(lcu Call) Calls {
 				return Calls{lcl.(Call), lcu}
 			})
-		}, reflect.TypeOf(Call{}))
+		}, reflect.TypeOf(Calls{}))
 
 		return gen.Struct(
 			reflect.TypeOf(Strategy{}),
@@ -451,7 +492,7 @@ func GenLongCallButterflyStrategy(ticker gopter.Gen) gopter.Gen {
 				"Lc":     lc,
 				"Type":   gen.Const(CallButterfly),
 				"Dir":    gen.Const(L)})
-	}, reflect.TypeOf(Call{}))
+	}, reflect.TypeOf(Strategy{}))
 }
 
 func GenShortCallButterflyStrategy(ticker gopter.Gen) gopter.Gen {
@@ -474,13 +515,13 @@ func GenShortCallButterflyStrategy(ticker gopter.Gen) gopter.Gen {
 func GenLongPutButterflyStrategy(ticker gopter.Gen) gopter.Gen {
 	sp := GenShortPut(ticker)
 	return sp.FlatMap(func(sp interface{}) gopter.Gen {
-		lpl := GenShortPutWithStrike(ticker, gen.Float64Range(0, sp.(Put).Strike-1))
-		lpu := GenShortPutWithStrike(ticker, gen.Float64Range(sp.(Put).Strike+1, MaxStrike))
+		lpl := GenLongPutWithStrike(ticker, gen.Float64Range(0, sp.(Put).Strike-1))
+		lpu := GenLongPutWithStrike(ticker, gen.Float64Range(sp.(Put).Strike+1, MaxStrike))
 		lp := lpl.FlatMap(func(lpl interface{}) gopter.Gen {
 			return lpu.Map(func(lpu Put) Puts {
 				return Puts{lpl.(Put), lpu}
 			})
-		}, reflect.TypeOf(Put{}))
+		}, reflect.TypeOf(Puts{}))
 
 		return gen.Struct(
 			reflect.TypeOf(Strategy{}),
@@ -490,7 +531,7 @@ func GenLongPutButterflyStrategy(ticker gopter.Gen) gopter.Gen {
 				"Lp":     lp,
 				"Type":   gen.Const(PutButterfly),
 				"Dir":    gen.Const(L)})
-	}, reflect.TypeOf(Put{}))
+	}, reflect.TypeOf(Strategy{}))
 }
 
 func GenShortPutButterflyStrategy(ticker gopter.Gen) gopter.Gen {
@@ -525,9 +566,9 @@ func GenLongJadeLizardStrategy(ticker gopter.Gen) gopter.Gen {
 						"Sc":     gen.SliceOfN(1, gen.Const(sc.(Call))),
 						"Type":   gen.Const(JadeLizard),
 						"Dir":    gen.Const(L)})
-			}, reflect.TypeOf(Call{}))
-		}, reflect.TypeOf(Call{}))
-	}, reflect.TypeOf(Put{}))
+			}, reflect.TypeOf(Strategy{}))
+		}, reflect.TypeOf(Strategy{}))
+	}, reflect.TypeOf(Strategy{}))
 }
 
 func GenShortJadeLizardStrategy(ticker gopter.Gen) gopter.Gen {
@@ -604,6 +645,212 @@ func GenShortNakedPutStrategy(ticker gopter.Gen) gopter.Gen {
 			"Dir":    gen.Const(S)})
 }
 
+func GenLongPutCalendarStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenShortPut(ticker).FlatMap(func(sp interface{}) gopter.Gen {
+		near := sp.(Put)
+		lp := GenLongPutWithStrike(ticker, gen.Const(near.Strike)).FlatMap(func(lp interface{}) gopter.Gen {
+			return GenExpirationsAfter(near.Expiration).Map(func(e time.Time) Puts {
+				far := lp.(Put)
+				far.Expiration = e
+				return Puts{far}
+			})
+		}, reflect.TypeOf(Puts{}))
+
+		return gen.Struct(
+			reflect.TypeOf(Strategy{}),
+			map[string]gopter.Gen{
+				"Ticker": ticker,
+				"Sp":     gen.Const(Puts{near}),
+				"Lp":     lp,
+				"Type":   gen.Const(CalendarSpread),
+				"Dir":    gen.Const(L)})
+	}, reflect.TypeOf(Strategy{}))
+}
+
+func GenShortPutCalendarStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenLongPutCalendarStrategy(ticker).Map(func(s Strategy) Strategy {
+		lp, sp := s.Lp[0], s.Sp[0]
+		lp.Expiration, sp.Expiration = sp.Expiration, lp.Expiration
+		return Strategy{
+			Ticker: s.Ticker,
+			Lp:     Puts{lp},
+			Sp:     Puts{sp},
+			Type:   CalendarSpread,
+			Dir:    S}
+	})
+}
+
+func GenLongCallCalendarStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenShortCall(ticker).FlatMap(func(sc interface{}) gopter.Gen {
+		near := sc.(Call)
+		lc := GenLongCallWithStrike(ticker, gen.Const(near.Strike)).FlatMap(func(lc interface{}) gopter.Gen {
+			return GenExpirationsAfter(near.Expiration).Map(func(e time.Time) Calls {
+				far := lc.(Call)
+				far.Expiration = e
+				return Calls{far}
+			})
+		}, reflect.TypeOf(Calls{}))
+
+		return gen.Struct(
+			reflect.TypeOf(Strategy{}),
+			map[string]gopter.Gen{
+				"Ticker": ticker,
+				"Sc":     gen.Const(Calls{near}),
+				"Lc":     lc,
+				"Type":   gen.Const(CalendarSpread),
+				"Dir":    gen.Const(L)})
+	}, reflect.TypeOf(Strategy{}))
+}
+
+func GenShortCallCalendarStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenLongCallCalendarStrategy(ticker).Map(func(s Strategy) Strategy {
+		lc, sc := s.Lc[0], s.Sc[0]
+		lc.Expiration, sc.Expiration = sc.Expiration, lc.Expiration
+		return Strategy{
+			Ticker: s.Ticker,
+			Lc:     Calls{lc},
+			Sc:     Calls{sc},
+			Type:   CalendarSpread,
+			Dir:    S}
+	})
+}
+
+func GenLongPutDiagonalStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenLongPutCalendarStrategy(ticker).FlatMap(func(s interface{}) gopter.Gen {
+		s1 := s.(Strategy)
+		strike := gen.Float64Range(MinStrike, MaxStrike).SuchThat(func(k float64) bool {
+			return k != s1.Sp[0].Strike
+		})
+		return strike.Map(func(k float64) Strategy {
+			lp := s1.Lp[0]
+			lp.Strike = k
+			return Strategy{
+				Ticker: s1.Ticker,
+				Lp:     Puts{lp},
+				Sp:     Puts{s1.Sp[0]},
+				Type:   DiagonalSpread,
+				Dir:    L}
+		})
+	}, reflect.TypeOf(Strategy{}))
+}
+
+func GenShortPutDiagonalStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenLongPutDiagonalStrategy(ticker).Map(func(s Strategy) Strategy {
+		lp, sp := s.Lp[0], s.Sp[0]
+		lp.Expiration, sp.Expiration = sp.Expiration, lp.Expiration
+		return Strategy{
+			Ticker: s.Ticker,
+			Lp:     Puts{lp},
+			Sp:     Puts{sp},
+			Type:   DiagonalSpread,
+			Dir:    S}
+	})
+}
+
+func GenLongCallDiagonalStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenLongCallCalendarStrategy(ticker).FlatMap(func(s interface{}) gopter.Gen {
+		s1 := s.(Strategy)
+		strike := gen.Float64Range(MinStrike, MaxStrike).SuchThat(func(k float64) bool {
+			return k != s1.Sc[0].Strike
+		})
+		return strike.Map(func(k float64) Strategy {
+			lc := s1.Lc[0]
+			lc.Strike = k
+			return Strategy{
+				Ticker: s1.Ticker,
+				Lc:     Calls{lc},
+				Sc:     Calls{s1.Sc[0]},
+				Type:   DiagonalSpread,
+				Dir:    L}
+		})
+	}, reflect.TypeOf(Strategy{}))
+}
+
+func GenShortCallDiagonalStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenLongCallDiagonalStrategy(ticker).Map(func(s Strategy) Strategy {
+		lc, sc := s.Lc[0], s.Sc[0]
+		lc.Expiration, sc.Expiration = sc.Expiration, lc.Expiration
+		return Strategy{
+			Ticker: s.Ticker,
+			Lc:     Calls{lc},
+			Sc:     Calls{sc},
+			Type:   DiagonalSpread,
+			Dir:    S}
+	})
+}
+
+// Generates a long and a short quantity in an unequal ratio, e.g. 1x2, 1x3 or 2x3. The first is the smaller.
+func GenRatio() gopter.Gen {
+	return gen.IntRange(1, 5).FlatMap(func(n interface{}) gopter.Gen {
+		n1 := n.(int)
+		return gen.IntRange(n1+1, 3*n1+1).Map(func(n2 int) []int {
+			return []int{n1, n2}
+		})
+	}, reflect.TypeOf([]int{}))
+}
+
+func GenCallRatioSpreadStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenLongCall(ticker).FlatMap(func(lc interface{}) gopter.Gen {
+		lc1 := lc.(Call)
+		sc := GenShortCallWithStrike(ticker, gen.Float64Range(lc1.Strike+1, MaxStrike+1))
+		return gopter.CombineGens(sc, GenRatio()).Map(func(vs []interface{}) Strategy {
+			sc1, r := vs[0].(Call), vs[1].([]int)
+			lc1.Quantity, sc1.Quantity = r[0], r[1]
+			return Strategy{
+				Ticker: lc1.Underlying.Ticker,
+				Lc:     Calls{lc1},
+				Sc:     Calls{sc1},
+				Type:   CallRatioSpread,
+				Dir:    S}
+		})
+	}, reflect.TypeOf(Strategy{}))
+}
+
+func GenCallBackspreadStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenCallRatioSpreadStrategy(ticker).Map(func(s Strategy) Strategy {
+		lc, sc := s.Lc[0], s.Sc[0]
+		lc.Strike, sc.Strike = sc.Strike, lc.Strike
+		lc.Quantity, sc.Quantity = sc.Quantity, lc.Quantity
+		return Strategy{
+			Ticker: s.Ticker,
+			Lc:     Calls{lc},
+			Sc:     Calls{sc},
+			Type:   CallBackspread,
+			Dir:    L}
+	})
+}
+
+func GenPutRatioSpreadStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenLongPut(ticker).FlatMap(func(lp interface{}) gopter.Gen {
+		lp1 := lp.(Put)
+		sp := GenShortPutWithStrike(ticker, gen.Float64Range(MinStrike-1, lp1.Strike-1))
+		return gopter.CombineGens(sp, GenRatio()).Map(func(vs []interface{}) Strategy {
+			sp1, r := vs[0].(Put), vs[1].([]int)
+			lp1.Quantity, sp1.Quantity = r[0], r[1]
+			return Strategy{
+				Ticker: lp1.Underlying.Ticker,
+				Lp:     Puts{lp1},
+				Sp:     Puts{sp1},
+				Type:   PutRatioSpread,
+				Dir:    S}
+		})
+	}, reflect.TypeOf(Strategy{}))
+}
+
+func GenPutBackspreadStrategy(ticker gopter.Gen) gopter.Gen {
+	return GenPutRatioSpreadStrategy(ticker).Map(func(s Strategy) Strategy {
+		lp, sp := s.Lp[0], s.Sp[0]
+		lp.Strike, sp.Strike = sp.Strike, lp.Strike
+		lp.Quantity, sp.Quantity = sp.Quantity, lp.Quantity
+		return Strategy{
+			Ticker: s.Ticker,
+			Lp:     Puts{lp},
+			Sp:     Puts{sp},
+			Type:   PutBackspread,
+			Dir:    L}
+	})
+}
+
 func GenLongCustomStrategy(ticker gopter.Gen) gopter.Gen {
 	s := gen.Struct(
 		reflect.TypeOf(Strategy{}),
@@ -638,6 +885,42 @@ func GenShortCustomStrategy(ticker gopter.Gen) gopter.Gen {
 	})
 }
 
+// Scales every leg of the generated strategies by a generated lot size and converts them to explicit sides.
+func GenLotStrategy(strategy gopter.Gen, lots gopter.Gen) gopter.Gen {
+	return strategy.FlatMap(func(s interface{}) gopter.Gen {
+		return lots.Map(func(n int) Strategy {
+			st := s.(Strategy)
+			lot := Strategy{Ticker: st.Ticker, Type: st.Type, Dir: st.Dir}
+			for _, x := range st.Stocks {
+				x = x.Explicit()
+				x.Shares *= n
+				lot.Stocks = append(lot.Stocks, x)
+			}
+			for _, p := range st.Lp {
+				p = p.Explicit()
+				p.Quantity = p.Qty() * n
+				lot.Lp = append(lot.Lp, p)
+			}
+			for _, p := range st.Sp {
+				p = p.Explicit()
+				p.Quantity = p.Qty() * n
+				lot.Sp = append(lot.Sp, p)
+			}
+			for _, c := range st.Sc {
+				c = c.Explicit()
+				c.Quantity = c.Qty() * n
+				lot.Sc = append(lot.Sc, c)
+			}
+			for _, c := range st.Lc {
+				c = c.Explicit()
+				c.Quantity = c.Qty() * n
+				lot.Lc = append(lot.Lc, c)
+			}
+			return lot
+		})
+	}, reflect.TypeOf(Strategy{}))
+}
+
 func GenStrategy(ticker gopter.Gen) gopter.Gen {
 	return gen.OneGenOf(
 		GenLongPutSpreadStrategy(ticker),
@@ -665,6 +948,18 @@ func GenStrategy(ticker gopter.Gen) gopter.Gen {
 		GenShortNakedCallStrategy(ticker),
 		GenLongNakedPutStrategy(ticker),
 		GenShortNakedPutStrategy(ticker),
+		GenLongPutCalendarStrategy(ticker),
+		GenShortPutCalendarStrategy(ticker),
+		GenLongCallCalendarStrategy(ticker),
+		GenShortCallCalendarStrategy(ticker),
+		GenLongPutDiagonalStrategy(ticker),
+		GenShortPutDiagonalStrategy(ticker),
+		GenLongCallDiagonalStrategy(ticker),
+		GenShortCallDiagonalStrategy(ticker),
+		GenCallRatioSpreadStrategy(ticker),
+		GenPutRatioSpreadStrategy(ticker),
+		GenCallBackspreadStrategy(ticker),
+		GenPutBackspreadStrategy(ticker),
 		GenLongCustomStrategy(ticker),
 		GenShortCustomStrategy(ticker))
 }
@@ -0,0 +1,107 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrSymbol = errors.New("unrecognized option symbol")
+
+// Ways brokers write an option symbol.
+type SymbolStyle int
+
+const (
+	// OCC OSI symbol: root padded to six characters, YYMMDD, C or P and the strike times 1000 in eight digits,
+	// e.g. "AAPL  240119C00190000".
+	OSI SymbolStyle = iota
+	// Dotted root, YYMMDD, C or P and the strike, e.g. ".AAPL240119C190".
+	Dotted SymbolStyle = iota
+	// Root, MM/DD/YYYY, strike and C or P separated by spaces, e.g. "AAPL 01/19/2024 190.00 C".
+	Spaced SymbolStyle = iota
+)
+
+var (
+	osiUnpadded = regexp.MustCompile(`^([A-Z][A-Z0-9.]{0,5})(\d{6})([CP])(\d{8})$`)
+	dotted      = regexp.MustCompile(`^\.([A-Z][A-Z0-9.]*?)(\d{6})([CP])(\d+(?:\.\d+)?)$`)
+	spaced      = regexp.MustCompile(`^([A-Z][A-Z0-9.]*) +(\d{1,2}/\d{1,2}/\d{4}) +(\d+(?:\.\d+)?) +(C|P|CALL|PUT)$`)
+)
+
+// Parses an option symbol in any SymbolStyle, and OSI symbols without padding, into a Put or Call with its
+// Underlying ticker, Strike and Expiration set. Price, Side and Quantity are left zero.
+func ParseOption(symbol string) (Asset, error) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+	var root, date, right, strike string
+	layout := "060102"
+	scale := 1.0
+
+	compact := strings.Replace(s, " ", "", -1)
+	if m := spaced.FindStringSubmatch(s); m != nil {
+		root, date, strike, right, layout = m[1], m[2], m[3], m[4][:1], "1/2/2006"
+	} else if m := osiUnpadded.FindStringSubmatch(compact); m != nil {
+		root, date, right, strike, scale = m[1], m[2], m[3], m[4], 1000
+	} else if m := dotted.FindStringSubmatch(compact); m != nil {
+		root, date, right, strike = m[1], m[2], m[3], m[4]
+	} else {
+		return nil, ErrSymbol
+	}
+
+	d, e := time.Parse(layout, date)
+	if e != nil {
+		return nil, ErrSymbol
+	}
+	k, e := strconv.ParseFloat(strike, 64)
+	if e != nil {
+		return nil, ErrSymbol
+	}
+	k /= scale
+	exp := ExpiresOn(d.Year(), d.Month(), d.Day())
+	if right == "C" {
+		return Call{Underlying: Stock{Ticker: root}, Strike: k, Expiration: exp}, nil
+	}
+	return Put{Underlying: Stock{Ticker: root}, Strike: k, Expiration: exp}, nil
+}
+
+// Writes the symbol of a Put or Call in a SymbolStyle. OSI symbols only fit roots of up to six characters and
+// strikes below 100000.
+func FormatOption(a Asset, style SymbolStyle) (string, error) {
+	switch a := a.(type) {
+	case Put:
+		return formatOption(a.Underlying.Ticker, a.Expiration, "P", a.Strike, style)
+	case Call:
+		return formatOption(a.Underlying.Ticker, a.Expiration, "C", a.Strike, style)
+	}
+	return "", ErrSymbol
+}
+
+// OCC OSI symbol of the put.
+func (p Put) OCC() string {
+	s, _ := FormatOption(p, OSI)
+	return s
+}
+
+// OCC OSI symbol of the call.
+func (c Call) OCC() string {
+	s, _ := FormatOption(c, OSI)
+	return s
+}
+
+func formatOption(root string, exp time.Time, right string, strike float64, style SymbolStyle) (string, error) {
+	switch style {
+	case OSI:
+		k := int64(math.Round(strike * 1000))
+		if len(root) > 6 || k < 0 || k >= 1e8 {
+			return "", ErrSymbol
+		}
+		return fmt.Sprintf("%-6s%s%s%08d", root, exp.Format("060102"), right, k), nil
+	case Dotted:
+		return fmt.Sprintf(".%s%s%s%s", root, exp.Format("060102"), right, strconv.FormatFloat(strike, 'f', -1, 64)), nil
+	case Spaced:
+		return fmt.Sprintf("%s %s %.2f %s", root, exp.Format("01/02/2006"), strike, right), nil
+	}
+	return "", ErrSymbol
+}
@@ -0,0 +1,87 @@
+package data
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestOCC(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	// Strikes listed in half dollar increments.
+	strikes := gen.IntRange(int(MinStrike)*2, int(MaxStrike)*2).Map(func(n int) float64 { return float64(n) / 2 })
+	roots := gen.OneConstOf("A", "AAPL", "SPY", "BRK.B", "GOOGL1")
+	jan19 := ExpiresOn(2024, time.January, 19)
+	examples := map[string]Asset{
+		"AAPL  240119C00190000":    Call{Underlying: Stock{Ticker: "AAPL"}, Strike: 190, Expiration: jan19},
+		"SPY240119P00452500":       Put{Underlying: Stock{Ticker: "SPY"}, Strike: 452.5, Expiration: jan19},
+		".AAPL240119C190":          Call{Underlying: Stock{Ticker: "AAPL"}, Strike: 190, Expiration: jan19},
+		".spy240119p452.5":         Put{Underlying: Stock{Ticker: "SPY"}, Strike: 452.5, Expiration: jan19},
+		"AAPL 01/19/2024 190.00 C": Call{Underlying: Stock{Ticker: "AAPL"}, Strike: 190, Expiration: jan19},
+		"AAPL 1/19/2024 190 Put":   Put{Underlying: Stock{Ticker: "AAPL"}, Strike: 190, Expiration: jan19},
+	}
+	var symbols []interface{}
+	for s := range examples {
+		symbols = append(symbols, s)
+	}
+
+	ps.Property("Symbols parse back to the leg in every style", prop.ForAll(
+		func(root string, strike float64, exp time.Time, call bool, style SymbolStyle) bool {
+			var a Asset = Put{Underlying: Stock{Ticker: root}, Strike: strike, Expiration: exp}
+			if call {
+				a = Call{Underlying: Stock{Ticker: root}, Strike: strike, Expiration: exp}
+			}
+			s, e := FormatOption(a, style)
+			if e != nil {
+				return false
+			}
+			b, e := ParseOption(s)
+			return e == nil && b == a
+		},
+		roots, strikes, GenExpirations(), gen.Bool(), gen.OneConstOf(OSI, Dotted, Spaced)))
+
+	ps.Property("OSI symbols are 21 characters", prop.ForAll(
+		func(p Put, c Call) bool {
+			return len(p.OCC()) == 21 && len(c.OCC()) == 21 && c.OCC()[12] == 'C' && p.OCC()[12] == 'P'
+		},
+		GenPut(gen.Const("AAA")), GenCall(gen.Const("AAA"))))
+
+	ps.Property("Broker variants parse", prop.ForAll(
+		func(s string) bool {
+			a, e := ParseOption(s)
+			return e == nil && a == examples[s]
+		},
+		gen.OneConstOf(symbols...)))
+
+	ps.Property("Malformed symbols are rejected", prop.ForAll(
+		func(s string) bool {
+			_, e := ParseOption(s)
+			return e == ErrSymbol
+		},
+		gen.OneGenOf(
+			gen.AlphaString(),
+			gen.OneConstOf("AAPL  240119X00190000", "AAPL 13/45/2024 190 C", ".AAPL2401C190", "TOOLONG240119C00190000"))))
+
+	ps.Property("Only options have symbols", prop.ForAll(
+		func(st Stock) bool {
+			_, e := FormatOption(st, OSI)
+			_, long := FormatOption(Call{Underlying: Stock{Ticker: "TOOLONG"}}, OSI)
+			return e == ErrSymbol && long == ErrSymbol
+		},
+		GenStock(gen.Const("AAA"))))
+
+	ps.Property("OSI symbols reject strikes that do not fit eight digits", prop.ForAll(
+		func(strike float64) bool {
+			_, e := FormatOption(Put{Underlying: Stock{Ticker: "NVR"}, Strike: strike, Expiration: jan19}, OSI)
+			_, ok := FormatOption(Put{Underlying: Stock{Ticker: "NVR"}, Strike: 99999.999, Expiration: jan19}, OSI)
+			return e == ErrSymbol && ok == nil
+		},
+		gen.OneGenOf(gen.Float64Range(99999.9995, 1e7), gen.Float64Range(-1e3, -0.0005))))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
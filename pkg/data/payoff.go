@@ -0,0 +1,157 @@
+package data
+
+import (
+	"math"
+	"sort"
+)
+
+// A leg reduced to what its value at expiration depends on. Stocks are treated as calls struck at zero.
+type exposure struct {
+	call    bool
+	strike  float64
+	units   float64 // Signed number of shares controlled: negative when short.
+	premium float64 // Entry price per share.
+}
+
+func (e exposure) payoffAt(u float64) float64 {
+	intrinsic := math.Max(u-e.strike, 0)
+	if !e.call {
+		intrinsic = math.Max(e.strike-u, 0)
+	}
+	return e.units * (intrinsic - e.premium)
+}
+
+// Option legs are signed by the side of the strategy they sit on, stocks by their own direction.
+func (s *Strategy) exposures() (es []exposure) {
+	for _, st := range s.Stocks {
+		n := float64(st.Qty())
+		if st.Dir() == S {
+			n = -n
+		}
+		es = append(es, exposure{true, 0, n, math.Abs(st.Price)})
+	}
+	for _, p := range s.Lp {
+		es = append(es, exposure{false, p.Strike, float64(p.Qty()) * p.Mult(), math.Abs(p.Price)})
+	}
+	for _, p := range s.Sp {
+		es = append(es, exposure{false, p.Strike, -float64(p.Qty()) * p.Mult(), math.Abs(p.Price)})
+	}
+	for _, c := range s.Lc {
+		es = append(es, exposure{true, c.Strike, float64(c.Qty()) * c.Mult(), math.Abs(c.Price)})
+	}
+	for _, c := range s.Sc {
+		es = append(es, exposure{true, c.Strike, -float64(c.Qty()) * c.Mult(), math.Abs(c.Price)})
+	}
+	return es
+}
+
+// Profit or loss of the strategy net of its entry cost with the underlying at price u at expiration.
+// Every option leg is treated as expiring at the same time.
+func (s *Strategy) PayoffAt(u float64) (payoff float64) {
+	for _, e := range s.exposures() {
+		payoff += e.payoffAt(u)
+	}
+	return payoff
+}
+
+// A point on the expiration payoff curve.
+type PayoffPoint struct {
+	Underlying float64
+	Payoff     float64
+}
+
+// Samples the expiration payoff at n evenly spaced underlying prices from lo to hi inclusive.
+func (s *Strategy) Payoff(lo, hi float64, n int) []PayoffPoint {
+	if n < 2 {
+		return []PayoffPoint{{lo, s.PayoffAt(lo)}}
+	}
+	ps := make([]PayoffPoint, n)
+	step := (hi - lo) / float64(n-1)
+	for i := range ps {
+		u := lo + float64(i)*step
+		if i == n-1 {
+			u = hi
+		}
+		ps[i] = PayoffPoint{u, s.PayoffAt(u)}
+	}
+	return ps
+}
+
+// Distinct option strikes of the strategy in ascending order. The payoff is linear between them.
+func (s *Strategy) Strikes() []float64 {
+	var ks []float64
+	for _, e := range s.exposures() {
+		if e.strike > 0 {
+			ks = append(ks, e.strike)
+		}
+	}
+	sort.Float64s(ks)
+
+	var ds []float64
+	for i, k := range ks {
+		if i == 0 || k != ks[i-1] {
+			ds = append(ds, k)
+		}
+	}
+	return ds
+}
+
+// Change in payoff per unit of underlying once it is above every strike.
+func (s *Strategy) upsideSlope() (slope float64) {
+	for _, e := range s.exposures() {
+		if e.call {
+			slope += e.units
+		}
+	}
+	return slope
+}
+
+// Underlying prices at which the expiration payoff is exactly zero, in ascending order.
+// Where the payoff is zero over a whole range only the ends of that range are returned.
+func (s *Strategy) Breakevens() []float64 {
+	ks := append([]float64{0}, s.Strikes()...)
+	vs := make([]float64, len(ks))
+	for i, k := range ks {
+		vs[i] = s.PayoffAt(k)
+	}
+
+	var bs []float64
+	add := func(b float64) {
+		if len(bs) == 0 || bs[len(bs)-1] != b {
+			bs = append(bs, b)
+		}
+	}
+	for i := range ks {
+		if vs[i] == 0 {
+			add(ks[i])
+		}
+		if i+1 < len(ks) && vs[i]*vs[i+1] < 0 {
+			add(ks[i] - vs[i]*(ks[i+1]-ks[i])/(vs[i+1]-vs[i]))
+		}
+	}
+
+	last, v, slope := ks[len(ks)-1], vs[len(vs)-1], s.upsideSlope()
+	if v*slope < 0 {
+		add(last - v/slope)
+	}
+	return bs
+}
+
+// Highest expiration payoff. Unbounded is true when profit grows without limit as the underlying rises.
+func (s *Strategy) MaxProfit() (profit float64, unbounded bool) {
+	profit = math.Inf(-1)
+	for _, k := range append([]float64{0}, s.Strikes()...) {
+		profit = math.Max(profit, s.PayoffAt(k))
+	}
+	return profit, s.upsideSlope() > 0
+}
+
+// Lowest expiration payoff, negative for a loss. Unbounded is true when losses grow without limit as the
+// underlying rises, as with naked calls and short stock.
+func (s *Strategy) MaxLoss() (loss float64, unbounded bool) {
+	loss = math.Inf(1)
+	for _, k := range append([]float64{0}, s.Strikes()...) {
+		loss = math.Min(loss, s.PayoffAt(k))
+	}
+	return loss, s.upsideSlope() < 0
+}
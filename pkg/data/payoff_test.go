@@ -0,0 +1,119 @@
+package data
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestPayoff(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	near := func(a, b float64) bool {
+		return math.Abs(a-b) < 1e-6*math.Max(1, math.Abs(b))
+	}
+
+	ps.Property("Long call pays intrinsic value less premium", prop.ForAll(
+		func(s Strategy, u float64) bool {
+			c := s.Lc[0]
+			want := 100 * (math.Max(u-c.Strike, 0) - c.Price)
+			return near(s.PayoffAt(u), want)
+		},
+		GenLongNakedCallStrategy(GenTicker()),
+		gen.Float64Range(0, 2*MaxStrike)))
+
+	ps.Property("Payoff samples the curve from lo to hi", prop.ForAll(
+		func(s Strategy, n int) bool {
+			pts := s.Payoff(MinStrike, MaxStrike, n)
+			if len(pts) != n || pts[0].Underlying != MinStrike || pts[n-1].Underlying != MaxStrike {
+				return false
+			}
+			for _, p := range pts {
+				if p.Payoff != s.PayoffAt(p.Underlying) {
+					return false
+				}
+			}
+			return true
+		},
+		GenStrategy(GenTicker()),
+		gen.IntRange(2, 50)))
+
+	ps.Property("Payoff is zero at every breakeven", prop.ForAll(
+		func(s Strategy) bool {
+			for _, b := range s.Breakevens() {
+				if !near(s.PayoffAt(b), 0) {
+					return false
+				}
+			}
+			return true
+		},
+		GenStrategy(GenTicker())))
+
+	ps.Property("Payoff keeps its sign between breakevens", prop.ForAll(
+		func(s Strategy) bool {
+			bs := append(append([]float64{0}, s.Breakevens()...), 2*MaxStrike+MaxStockPrice)
+			for i := 0; i+1 < len(bs); i++ {
+				a, b := bs[i], bs[i+1]
+				m := s.PayoffAt((a + b) / 2)
+				q1, q3 := s.PayoffAt(a+(b-a)/4), s.PayoffAt(b-(b-a)/4)
+				if m*q1 < 0 || m*q3 < 0 {
+					return false
+				}
+			}
+			return true
+		},
+		GenStrategy(GenTicker())))
+
+	ps.Property("Naked short calls and short stock have unbounded loss", prop.ForAll(
+		func(s Strategy) bool {
+			_, unbounded := s.MaxLoss()
+			_, gains := s.MaxProfit()
+			return unbounded && !gains
+		},
+		gen.OneGenOf(
+			GenShortNakedCallStrategy(GenTicker()),
+			GenShortNakedStockStrategy(GenTicker()))))
+
+	ps.Property("Long calls and long stock have unbounded profit", prop.ForAll(
+		func(s Strategy) bool {
+			_, unbounded := s.MaxProfit()
+			return unbounded
+		},
+		gen.OneGenOf(
+			GenLongNakedCallStrategy(GenTicker()),
+			GenLongNakedStockStrategy(GenTicker()))))
+
+	ps.Property("Iron condors and butterflies are bounded and bracket the sampled curve", prop.ForAll(
+		func(s Strategy) bool {
+			p, pu := s.MaxProfit()
+			l, lu := s.MaxLoss()
+			if pu || lu {
+				return false
+			}
+			for _, pt := range s.Payoff(0, 2*MaxStrike, 200) {
+				if pt.Payoff > p+1e-6 || pt.Payoff < l-1e-6 {
+					return false
+				}
+			}
+			return true
+		},
+		gen.OneGenOf(
+			GenShortIronCondorStrategy(GenTicker()),
+			GenLongIronButterflyStrategy(GenTicker()),
+			GenLongCallButterflyStrategy(GenTicker()),
+			GenShortPutSpreadStrategy(GenTicker()))))
+
+	ps.Property("Short put max profit is the premium received", prop.ForAll(
+		func(s Strategy) bool {
+			p, _ := s.MaxProfit()
+			return near(p, -100*s.Sp[0].Price)
+		},
+		GenShortNakedPutStrategy(GenTicker())))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
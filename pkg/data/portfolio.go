@@ -0,0 +1,85 @@
+package data
+
+import (
+	"sort"
+)
+
+// Positions across many tickers, each ticker's legs grouped into recognized strategies.
+type Portfolio struct {
+	Strategies []Strategy
+}
+
+// Creates a portfolio from a flat list of assets. Assets are partitioned by underlying ticker and the legs of
+// each ticker are grouped into named strategies by Decompose, e.g. a covered call and a leftover short put,
+// rather than a single Custom strategy.
+func NewPortfolio(ss Stocks, ps Puts, cs Calls) (Portfolio, error) {
+	p := Portfolio{}
+
+	byTicker := make(map[string][]Asset)
+	for _, st := range ss {
+		byTicker[st.Ticker] = append(byTicker[st.Ticker], st)
+	}
+	for _, o := range ps {
+		byTicker[o.Underlying.Ticker] = append(byTicker[o.Underlying.Ticker], o)
+	}
+	for _, o := range cs {
+		byTicker[o.Underlying.Ticker] = append(byTicker[o.Underlying.Ticker], o)
+	}
+
+	tickers := make([]string, 0, len(byTicker))
+	for t := range byTicker {
+		tickers = append(tickers, t)
+	}
+	sort.Strings(tickers)
+
+	for _, t := range tickers {
+		strategies, e := decompose(byTicker[t], DefaultDecomposeSteps)
+		if e != nil {
+			return Portfolio{}, e
+		}
+		p.Strategies = append(p.Strategies, strategies...)
+	}
+	return p, nil
+}
+
+// Returns the sorted tickers held in the portfolio.
+func (p *Portfolio) Tickers() []string {
+	var ts []string
+	for _, s := range p.Strategies {
+		if len(ts) == 0 || ts[len(ts)-1] != s.Ticker {
+			ts = append(ts, s.Ticker)
+		}
+	}
+	return ts
+}
+
+// Returns the strategies held on a ticker.
+func (p *Portfolio) Ticker(ticker string) []Strategy {
+	var ss []Strategy
+	for _, s := range p.Strategies {
+		if s.Ticker == ticker {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
+
+// Splits assets into their stocks, puts and calls.
+func split(as []Asset) (ss Stocks, ps Puts, cs Calls) {
+	for _, a := range as {
+		switch a := a.(type) {
+		case Stock:
+			ss = append(ss, a)
+		case Put:
+			ps = append(ps, a)
+		case Call:
+			cs = append(cs, a)
+		}
+	}
+	return ss, ps, cs
+}
+
+func newStrategy(as []Asset) (Strategy, error) {
+	ss, ps, cs := split(as)
+	return NewStrategy(ss, ps, cs)
+}
@@ -0,0 +1,75 @@
+package data
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"os"
+	"testing"
+)
+
+// Flattens strategies into their legs.
+func legs(ss ...Strategy) (Stocks, Puts, Calls) {
+	var sts Stocks
+	var ps Puts
+	var cs Calls
+	for _, s := range ss {
+		sts = append(sts, s.Stocks...)
+		ps = append(append(ps, s.Lp...), s.Sp...)
+		cs = append(append(cs, s.Lc...), s.Sc...)
+	}
+	return sts, ps, cs
+}
+
+func TestPortfolio(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	ps.Property("Portfolio keeps every leg and groups them by ticker", prop.ForAll(
+		func(a, b, c Strategy) bool {
+			p, e := NewPortfolio(legs(a, b, c))
+			if e != nil {
+				return false
+			}
+			n := 0
+			for _, s := range p.Strategies {
+				n += len(s.Stocks) + s.CountOptions()
+			}
+			ts := p.Tickers()
+			return n == len(a.Stocks)+a.CountOptions()+len(b.Stocks)+b.CountOptions()+len(c.Stocks)+c.CountOptions() &&
+				len(ts) == 3 && ts[0] == "AAA" && ts[1] == "BBB" && ts[2] == "CCC"
+		},
+		GenStrategy(gen.Const("CCC")).SuchThat(func(s Strategy) bool { return !s.empty() }),
+		GenStrategy(gen.Const("AAA")).SuchThat(func(s Strategy) bool { return !s.empty() }),
+		GenStrategy(gen.Const("BBB")).SuchThat(func(s Strategy) bool { return !s.empty() })))
+
+	ps.Property("Recognized strategies on different tickers keep their type", prop.ForAll(
+		func(a, b Strategy) bool {
+			p, e := NewPortfolio(legs(a, b))
+			if e != nil {
+				return false
+			}
+			sa, sb := p.Ticker("AAA"), p.Ticker("BBB")
+			return len(sa) == 1 && len(sb) == 1 && sa[0].Type == IronCondor && sb[0].Type == CoveredCall
+		},
+		GenShortIronCondorStrategy(gen.Const("AAA")),
+		GenShortCoveredCallStrategy(gen.Const("BBB"))))
+
+	ps.Property("Covered call with a short put splits into a covered call and a naked put", prop.ForAll(
+		func(cc, np Strategy) bool {
+			p, e := NewPortfolio(legs(cc, np))
+			if e != nil || len(p.Strategies) != 2 {
+				return false
+			}
+			found := map[Type]bool{}
+			for _, s := range p.Strategies {
+				found[s.Type] = true
+			}
+			return found[CoveredCall] && found[NakedPut]
+		},
+		GenShortCoveredCallStrategy(gen.Const("AAA")),
+		GenShortNakedPutStrategy(gen.Const("AAA"))))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,134 @@
+package data
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Recognizes whether a strategy is of a particular Type and, if so, its Direction.
+type Recognizer interface {
+	Recognize(s *Strategy) (Direction, bool)
+}
+
+// Adapts an ordinary function to the Recognizer interface.
+type RecognizerFunc func(s *Strategy) (Direction, bool)
+
+func (f RecognizerFunc) Recognize(s *Strategy) (Direction, bool) {
+	return f(s)
+}
+
+// Priority of Custom, after every built-in Type.
+const customPriority = 1000
+
+// A Type with the condition under which a strategy is that Type.
+type condition struct {
+	kind     Type
+	priority int
+	match    func(*Strategy) (Direction, bool)
+}
+
+var (
+	builtinsOnce sync.Once
+	builtins     []condition
+)
+
+// Returns the conditions of every built-in and registered Type ordered by priority, ties broken by Type.
+// The returned slice must not be modified.
+func registry() []condition {
+	builtinsOnce.Do(func() {
+		builtins = conditions()
+	})
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(registered) == 0 {
+		return builtins
+	}
+
+	r := append(make([]condition, 0, len(builtins)+len(registered)), builtins...)
+	for _, rg := range registered {
+		r = append(r, rg.condition)
+	}
+	sortConditions(r)
+	return r
+}
+
+func sortConditions(r []condition) {
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].priority != r[j].priority {
+			return r[i].priority < r[j].priority
+		}
+		return r[i].kind < r[j].kind
+	})
+}
+
+// A Type added at runtime through Register.
+type registration struct {
+	name string
+	condition
+}
+
+var (
+	mu         sync.RWMutex
+	registered []registration
+)
+
+// Adds a new strategy Type recognized by r and tried in order of priority alongside the built-in Types,
+// whose priorities run from 0 (Empty) to 190 (NakedStock). The returned Type prints as name and is
+// assigned by CheckKind and NewStrategy whenever r matches first.
+func Register(name string, priority int, r Recognizer) (Type, error) {
+	if name == "" {
+		return Custom, errors.New("strategy type name must not be empty")
+	}
+	if r == nil {
+		return Custom, errors.New("strategy type recognizer must not be nil")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, n := range typeNames {
+		if n == name {
+			return Custom, errors.New("strategy type name already in use: " + name)
+		}
+	}
+	for _, rg := range registered {
+		if rg.name == name {
+			return Custom, errors.New("strategy type name already in use: " + name)
+		}
+	}
+
+	t := Type(len(typeNames) + len(registered))
+	registered = append(registered, registration{name, condition{t, priority, r.Recognize}})
+	return t, nil
+}
+
+func registeredName(t Type) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	i := int(t) - len(typeNames)
+	if i < 0 || i >= len(registered) {
+		return "Unregistered"
+	}
+	return registered[i].name
+}
+
+// Returns the built-in or registered Type printed as name.
+func typeNamed(name string) (Type, bool) {
+	for t, n := range typeNames {
+		if n == name {
+			return Type(t), true
+		}
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, rg := range registered {
+		if rg.name == name {
+			return rg.kind, true
+		}
+	}
+	return Custom, false
+}
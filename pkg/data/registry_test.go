@@ -0,0 +1,89 @@
+package data
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"os"
+	"testing"
+)
+
+// A short straddle with a long call above it, which would otherwise pass for a jade lizard.
+func bigLizard(s *Strategy) (Direction, bool) {
+	if !s.hasNStocks(0) || !s.hasNPuts(0, 1) || !s.hasNCalls(1, 1) {
+		return None, false
+	}
+	if s.Sp[0].Strike == s.Sc[0].Strike && s.Sc[0].Strike < s.Lc[0].Strike {
+		return S, true
+	}
+	return None, false
+}
+
+// Returns a function that forgets the Types registered since, so a test leaves the registry as it found it.
+func restoreRegistry() func() {
+	mu.RLock()
+	n := len(registered)
+	mu.RUnlock()
+	return func() {
+		mu.Lock()
+		registered = registered[:n]
+		mu.Unlock()
+	}
+}
+
+func TestRegistry(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	t.Cleanup(restoreRegistry())
+	lizard, e := Register("BigLizard", priorityOf(JadeLizard)-1, RecognizerFunc(bigLizard))
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	ps.Property("Every built-in Type is tried once, in priority order", prop.ForAll(
+		func(t Type) bool {
+			n := 0
+			cs := conditions()
+			for i, c := range cs {
+				if c.kind == t {
+					n++
+				}
+				if i > 0 && c.priority <= cs[i-1].priority {
+					return false
+				}
+			}
+			return n == 1 && cs[len(cs)-1].kind == Custom
+		},
+		GenType()))
+
+	ps.Property("Registered types print their name", prop.ForAll(
+		func(n int) bool {
+			return lizard.String() == "BigLizard" && (lizard+Type(n)).String() == "Unregistered"
+		},
+		gen.IntRange(1, 100)))
+
+	ps.Property("Register rejects names already in use", prop.ForAll(
+		func(t Type) bool {
+			_, e1 := Register(t.String(), 0, RecognizerFunc(bigLizard))
+			_, e2 := Register("BigLizard", 0, RecognizerFunc(bigLizard))
+			return e1 != nil && e2 != nil
+		},
+		gen.IntRange(0, len(typeNames)-1).Map(func(i int) Type { return Type(i) })))
+
+	ps.Property("NewStrategy honours registered types ahead of lower priorities", prop.ForAll(
+		func(s Strategy) bool {
+			sp := s.Sp[0]
+			sp.Strike = s.Sc[0].Strike
+			st, e := NewStrategy(nil, Puts{sp}, Calls{s.Sc[0], s.Lc[0]})
+			if e != nil || st.Type != lizard || st.Dir != S {
+				return false
+			}
+			ms := st.CheckAll()
+			return len(ms) == 2 && ms[1].Type == JadeLizard
+		},
+		GenShortJadeLizardStrategy(GenTicker())))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -3,55 +3,78 @@ package data
 import (
 	"errors"
 	"sort"
+	"time"
 )
 
 type Type int
 
 const (
-	Spread        Type = iota
-	Strangle      Type = iota
-	Straddle      Type = iota
-	CoveredCall   Type = iota
-	CoveredPut    Type = iota
-	IronCondor    Type = iota
-	IronButterfly Type = iota
-	CallButterfly Type = iota
-	PutButterfly  Type = iota
-	JadeLizard    Type = iota
-	NakedStock    Type = iota
-	NakedCall     Type = iota
-	NakedPut      Type = iota
-	Custom        Type = iota
-	Empty         Type = iota
+	Spread          Type = iota
+	Strangle        Type = iota
+	Straddle        Type = iota
+	CoveredCall     Type = iota
+	CoveredPut      Type = iota
+	IronCondor      Type = iota
+	IronButterfly   Type = iota
+	CallButterfly   Type = iota
+	PutButterfly    Type = iota
+	JadeLizard      Type = iota
+	NakedStock      Type = iota
+	NakedCall       Type = iota
+	NakedPut        Type = iota
+	CalendarSpread  Type = iota
+	DiagonalSpread  Type = iota
+	CallRatioSpread Type = iota
+	PutRatioSpread  Type = iota
+	CallBackspread  Type = iota
+	PutBackspread   Type = iota
+	Custom          Type = iota
+	Empty           Type = iota
 )
 
+// Names of the built-in Types, indexed by Type. Types from Register are numbered after the last of them.
+var typeNames = [...]string{
+	Spread:          "Spread",
+	Strangle:        "Strangle",
+	Straddle:        "Straddle",
+	CoveredCall:     "CoveredCall",
+	CoveredPut:      "CoveredPut",
+	IronCondor:      "IronCondor",
+	IronButterfly:   "IronButterfly",
+	CallButterfly:   "CallButterfly",
+	PutButterfly:    "PutButterfly",
+	JadeLizard:      "JadeLizard",
+	NakedStock:      "NakedStock",
+	NakedCall:       "NakedCall",
+	NakedPut:        "NakedPut",
+	CalendarSpread:  "CalendarSpread",
+	DiagonalSpread:  "DiagonalSpread",
+	CallRatioSpread: "CallRatioSpread",
+	PutRatioSpread:  "PutRatioSpread",
+	CallBackspread:  "CallBackspread",
+	PutBackspread:   "PutBackspread",
+	Custom:          "Custom",
+	Empty:           "Empty",
+}
+
 func (t Type) String() string {
-	return []string{
-		"Spread",
-		"Strangle",
-		"Straddle",
-		"CoveredCall",
-		"CoveredPut",
-		"IronCondor",
-		"IronButterfly",
-		"CallButterfly",
-		"PutButterfly",
-		"JadeLizard",
-		"NakedStock",
-		"NakedCall",
-		"NakedPut",
-		"Custom",
-		"Empty"}[t]
-}
-
-// Aggregates kinds and directions of strategies and returns the conditions under which they are valid.
-func conditions() map[Type]func(*Strategy) (Direction, bool) {
-	c := make(map[Type]func(*Strategy) (Direction, bool))
-
-	c[Spread] = func(s *Strategy) (Direction, bool) {
+	if t < 0 || int(t) >= len(typeNames) {
+		return registeredName(t)
+	}
+	return typeNames[t]
+}
+
+// Returns the built-in Types with the conditions under which a strategy is that Type and its Direction, in
+// the order they are tried. When a strategy satisfies several Types the first one listed wins, so the most
+// specific Types come first. Custom comes last and takes whatever nothing else matches.
+func conditions() []condition {
+	spread := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
+		if !s.singleExpiration() || !s.evenQuantities() {
+			return None, false
+		}
 
 		put := s.hasNPuts(1, 1) && s.hasNCalls(0, 0)
 		call := s.hasNPuts(0, 0) && s.hasNCalls(1, 1)
@@ -72,14 +95,17 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[Empty] = func(s *Strategy) (Direction, bool) {
+	empty := func(s *Strategy) (Direction, bool) {
 		return None, s.empty()
 	}
 
-	c[Strangle] = func(s *Strategy) (Direction, bool) {
+	strangle := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
+		if !s.singleExpiration() || !s.evenQuantities() {
+			return None, false
+		}
 		short := s.hasNPuts(0, 1) && s.hasNCalls(1, 0)
 		long := s.hasNPuts(1, 0) && s.hasNCalls(0, 1)
 
@@ -91,10 +117,13 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[Straddle] = func(s *Strategy) (Direction, bool) {
+	straddle := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
+		if !s.singleExpiration() || !s.evenQuantities() {
+			return None, false
+		}
 		short := s.hasNPuts(0, 1) && s.hasNCalls(1, 0)
 		long := s.hasNPuts(1, 0) && s.hasNCalls(0, 1)
 
@@ -106,53 +135,48 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[CoveredCall] = func(s *Strategy) (Direction, bool) {
+	coveredCall := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(1) {
 			return None, false
 		}
 
-		if s.Stocks[0].Shares != 100 {
-			return None, false
-		}
-
 		if !s.hasNPuts(0, 0) {
 			return None, false
 		}
 
-		if s.Stocks[0].Dir() == L && s.hasNCalls(1, 0) {
+		if s.Stocks[0].Dir() == L && s.hasNCalls(1, 0) && s.covers(s.Sc[0].Qty(), s.Sc[0].Mult()) {
 			return S, true
-		} else if s.Stocks[0].Dir() == S && s.hasNCalls(0, 1) {
+		} else if s.Stocks[0].Dir() == S && s.hasNCalls(0, 1) && s.covers(s.Lc[0].Qty(), s.Lc[0].Mult()) {
 			return L, true
 		}
 
 		return None, false
 	}
 
-	c[CoveredPut] = func(s *Strategy) (Direction, bool) {
+	coveredPut := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(1) {
 			return None, false
 		}
 
-		if s.Stocks[0].Shares != 100 {
-			return None, false
-		}
-
 		if !s.hasNCalls(0, 0) {
 			return None, false
 		}
 
-		if s.Stocks[0].Dir() == L && s.hasNPuts(1, 0) {
+		if s.Stocks[0].Dir() == L && s.hasNPuts(1, 0) && s.covers(s.Lp[0].Qty(), s.Lp[0].Mult()) {
 			return L, true
-		} else if s.Stocks[0].Dir() == S && s.hasNPuts(0, 1) {
+		} else if s.Stocks[0].Dir() == S && s.hasNPuts(0, 1) && s.covers(s.Sp[0].Qty(), s.Sp[0].Mult()) {
 			return S, true
 		}
 		return None, false
 	}
 
-	c[IronCondor] = func(s *Strategy) (Direction, bool) {
+	ironCondor := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
+		if !s.singleExpiration() || !s.evenQuantities() {
+			return None, false
+		}
 		if !(s.hasNPuts(1, 1) && s.hasNCalls(1, 1)) {
 			return None, false
 		}
@@ -167,10 +191,13 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[IronButterfly] = func(s *Strategy) (Direction, bool) {
+	ironButterfly := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
+		if !s.singleExpiration() || !s.evenQuantities() {
+			return None, false
+		}
 		if !(s.hasNPuts(1, 1) && s.hasNCalls(1, 1)) {
 			return None, false
 		}
@@ -185,23 +212,19 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[CallButterfly] = func(s *Strategy) (Direction, bool) {
+	callButterfly := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
-		if !s.hasNPuts(0, 0) {
+		if !s.singleExpiration() {
 			return None, false
 		}
-		if !s.hasNCalls(2, 2) {
+		if !s.hasNPuts(0, 0) {
 			return None, false
 		}
 
-		long := s.Sc[0].Strike == s.Sc[1].Strike &&
-			s.Lc[0].Strike < s.Sc[0].Strike &&
-			s.Sc[1].Strike < s.Lc[1].Strike
-		short := s.Lc[0].Strike == s.Lc[1].Strike &&
-			s.Sc[0].Strike < s.Lc[0].Strike &&
-			s.Lc[1].Strike < s.Sc[1].Strike
+		long := butterfly(s.Lc.ladder(), s.Sc.ladder())
+		short := butterfly(s.Sc.ladder(), s.Lc.ladder())
 
 		if long {
 			return L, true
@@ -212,23 +235,19 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[PutButterfly] = func(s *Strategy) (Direction, bool) {
+	putButterfly := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
-		if !s.hasNCalls(0, 0) {
+		if !s.singleExpiration() {
 			return None, false
 		}
-		if !s.hasNPuts(2, 2) {
+		if !s.hasNCalls(0, 0) {
 			return None, false
 		}
 
-		long := s.Sp[0].Strike == s.Sp[1].Strike &&
-			s.Lp[0].Strike < s.Sp[0].Strike &&
-			s.Sp[1].Strike < s.Lp[1].Strike
-		short := s.Lp[0].Strike == s.Lp[1].Strike &&
-			s.Sp[0].Strike < s.Lp[0].Strike &&
-			s.Lp[1].Strike < s.Sp[1].Strike
+		long := butterfly(s.Lp.ladder(), s.Sp.ladder())
+		short := butterfly(s.Sp.ladder(), s.Lp.ladder())
 
 		if long {
 			return L, true
@@ -238,10 +257,13 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[JadeLizard] = func(s *Strategy) (Direction, bool) {
+	jadeLizard := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
+		if !s.singleExpiration() || !s.evenQuantities() {
+			return None, false
+		}
 		if !s.hasNCalls(1, 1) {
 			return None, false
 		}
@@ -257,7 +279,7 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[NakedStock] = func(s *Strategy) (Direction, bool) {
+	nakedStock := func(s *Strategy) (Direction, bool) {
 		if s.hasAnyOptions() {
 			return None, false
 		}
@@ -267,13 +289,13 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[NakedCall] = func(s *Strategy) (Direction, bool) {
+	nakedCall := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
 		put := s.hasNPuts(0, 0)
-		long := len(s.Lc) == 1
-		short := len(s.Sc) == 1
+		long := s.hasNCalls(0, 1)
+		short := s.hasNCalls(1, 0)
 
 		if put && long {
 			return L, true
@@ -283,7 +305,7 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[NakedPut] = func(s *Strategy) (Direction, bool) {
+	nakedPut := func(s *Strategy) (Direction, bool) {
 		if !s.hasNStocks(0) {
 			return None, false
 		}
@@ -301,69 +323,332 @@ func conditions() map[Type]func(*Strategy) (Direction, bool) {
 		return None, false
 	}
 
-	c[Custom] = func(s *Strategy) (Direction, bool) {
-		for k, v := range c {
-			if k == Custom {
-				continue
-			}
-			if _, ok := v(s); ok {
-				return None, false
-			}
+	calendarSpread := func(s *Strategy) (Direction, bool) {
+		near, far, ok := s.timeSpread()
+		if !ok || near.strike != far.strike {
+			return None, false
 		}
+		return far.dir, true
+	}
 
-		// Compute direction
-		price := s.Price()
+	diagonalSpread := func(s *Strategy) (Direction, bool) {
+		near, far, ok := s.timeSpread()
+		if !ok || near.strike == far.strike {
+			return None, false
+		}
+		return far.dir, true
+	}
+
+	callRatioSpread := func(s *Strategy) (Direction, bool) {
+		if !s.hasNStocks(0) || !s.hasNPuts(0, 0) {
+			return None, false
+		}
+		if !s.singleExpiration() {
+			return None, false
+		}
+		l, sh, ok := ratio(s.Lc.ladder(), s.Sc.ladder())
+		if ok && l.strike < sh.strike && l.qty < sh.qty {
+			return S, true
+		}
+		return None, false
+	}
+
+	putRatioSpread := func(s *Strategy) (Direction, bool) {
+		if !s.hasNStocks(0) || !s.hasNCalls(0, 0) {
+			return None, false
+		}
+		if !s.singleExpiration() {
+			return None, false
+		}
+		l, sh, ok := ratio(s.Lp.ladder(), s.Sp.ladder())
+		if ok && sh.strike < l.strike && l.qty < sh.qty {
+			return S, true
+		}
+		return None, false
+	}
 
-		if price > 0 {
+	callBackspread := func(s *Strategy) (Direction, bool) {
+		if !s.hasNStocks(0) || !s.hasNPuts(0, 0) {
+			return None, false
+		}
+		if !s.singleExpiration() {
+			return None, false
+		}
+		l, sh, ok := ratio(s.Lc.ladder(), s.Sc.ladder())
+		if ok && sh.strike < l.strike && sh.qty < l.qty {
 			return L, true
 		}
-		return S, false
+		return None, false
 	}
 
-	return c
+	putBackspread := func(s *Strategy) (Direction, bool) {
+		if !s.hasNStocks(0) || !s.hasNCalls(0, 0) {
+			return None, false
+		}
+		if !s.singleExpiration() {
+			return None, false
+		}
+		l, sh, ok := ratio(s.Lp.ladder(), s.Sp.ladder())
+		if ok && l.strike < sh.strike && sh.qty < l.qty {
+			return L, true
+		}
+		return None, false
+	}
+
+	custom := func(s *Strategy) (Direction, bool) {
+		if s.Price() > 0 {
+			return L, true
+		}
+		return S, true
+	}
+
+	return []condition{
+		{Empty, 0, empty},
+		{IronCondor, 10, ironCondor},
+		{IronButterfly, 20, ironButterfly},
+		{JadeLizard, 30, jadeLizard},
+		{CallButterfly, 40, callButterfly},
+		{PutButterfly, 50, putButterfly},
+		{CalendarSpread, 60, calendarSpread},
+		{DiagonalSpread, 70, diagonalSpread},
+		{CallRatioSpread, 80, callRatioSpread},
+		{PutRatioSpread, 90, putRatioSpread},
+		{CallBackspread, 100, callBackspread},
+		{PutBackspread, 110, putBackspread},
+		{Straddle, 120, straddle},
+		{Strangle, 130, strangle},
+		{Spread, 140, spread},
+		{CoveredCall, 150, coveredCall},
+		{CoveredPut, 160, coveredPut},
+		{NakedCall, 170, nakedCall},
+		{NakedPut, 180, nakedPut},
+		{NakedStock, 190, nakedStock},
+		{Custom, customPriority, custom},
+	}
 }
 
 // Container for all strategy kinds.
 // Note it is not 'strategy safe' to construct this via the standard constructor.
 // Use the NewStrategy method to allow for dynamic inference of strategy Type and direction.
 type Strategy struct {
-	Ticker string
-	Stocks Stocks
-	Lp     Puts
-	Sp     Puts
-	Sc     Calls
-	Lc     Calls
-	Type   Type
-	Dir    Direction
+	Ticker string    `json:"ticker" yaml:"ticker"`
+	Stocks Stocks    `json:"stocks,omitempty" yaml:"stocks,omitempty"`
+	Lp     Puts      `json:"longPuts,omitempty" yaml:"longPuts,omitempty"`
+	Sp     Puts      `json:"shortPuts,omitempty" yaml:"shortPuts,omitempty"`
+	Sc     Calls     `json:"shortCalls,omitempty" yaml:"shortCalls,omitempty"`
+	Lc     Calls     `json:"longCalls,omitempty" yaml:"longCalls,omitempty"`
+	Type   Type      `json:"type" yaml:"type"`
+	Dir    Direction `json:"dir" yaml:"dir"`
 }
 
 // Determines the Type of a strategy. Defaults to Custom if there are no other matches.
+// When several Types match, the one with the highest priority is returned.
 func (s *Strategy) CheckKind() (Type, Direction) {
-	ks := conditions()
-	for k, v := range ks {
-		d, ok := v(s)
-		if ok {
-			return k, d
+	ms := s.CheckAll()
+	return ms[0].Type, ms[0].Dir
+}
+
+// A Type a strategy satisfies and its Direction as that Type.
+type Match struct {
+	Type Type
+	Dir  Direction
+}
+
+// Returns every Type a strategy satisfies in priority order. Custom is returned alone when nothing else matches.
+func (s *Strategy) CheckAll() []Match {
+	var ms []Match
+	var custom condition
+	for _, c := range registry() {
+		if c.kind == Custom {
+			custom = c
+			continue
+		}
+		if d, ok := c.match(s); ok {
+			ms = append(ms, Match{c.kind, d})
 		}
 	}
 
-	// In case Type doesn't get selected above, return Custom.
-	d, _ := ks[Custom](s)
-	return Custom, d
+	// In case no Type gets selected above, return Custom.
+	if len(ms) == 0 {
+		d, _ := custom.match(s)
+		ms = append(ms, Match{Custom, d})
+	}
+	return ms
 }
 
+// Net cost of the strategy with every leg scaled by its quantity and contract multiplier.
 func (s *Strategy) Price() float64 {
-	return s.PriceOptions() + s.Stocks.Price()
+	return s.PriceOptions() + s.Stocks.Cost()
 }
 
 func (s *Strategy) PriceOptions() (price float64) {
-	return s.Lp.Price() + s.Sp.Price() + s.Sc.Price() + s.Lc.Price()
+	return s.Lp.Cost() + s.Sp.Cost() + s.Sc.Cost() + s.Lc.Cost()
 }
 
 func (s *Strategy) CountOptions() (count int) {
 	return len(s.Lp) + len(s.Sp) + len(s.Sc) + len(s.Lc)
 }
 
+// Returns the distinct expiration dates of all option legs in ascending order.
+func (s *Strategy) Expirations() []time.Time {
+	var es []time.Time
+	add := func(e time.Time) {
+		for _, x := range es {
+			if x.Equal(e) {
+				return
+			}
+		}
+		es = append(es, e)
+	}
+	for _, p := range s.Lp {
+		add(p.Expiration)
+	}
+	for _, p := range s.Sp {
+		add(p.Expiration)
+	}
+	for _, c := range s.Sc {
+		add(c.Expiration)
+	}
+	for _, c := range s.Lc {
+		add(c.Expiration)
+	}
+	sort.Slice(es, func(i, j int) bool { return es[i].Before(es[j]) })
+	return es
+}
+
+// True when every option leg holds the same number of contracts, i.e. the legs are in a 1:1 ratio.
+func (s *Strategy) evenQuantities() bool {
+	q := 0
+	for _, n := range s.quantities() {
+		if q != 0 && n != q {
+			return false
+		}
+		q = n
+	}
+	return true
+}
+
+// Contract counts of every option leg.
+func (s *Strategy) quantities() (qs []int) {
+	for _, p := range s.Lp {
+		qs = append(qs, p.Qty())
+	}
+	for _, p := range s.Sp {
+		qs = append(qs, p.Qty())
+	}
+	for _, c := range s.Sc {
+		qs = append(qs, c.Qty())
+	}
+	for _, c := range s.Lc {
+		qs = append(qs, c.Qty())
+	}
+	return qs
+}
+
+// True when the single stock position holds exactly the shares deliverable against qty contracts.
+func (s *Strategy) covers(qty int, mult float64) bool {
+	return float64(s.Stocks[0].Shares) == float64(qty)*mult
+}
+
+// A strike and the total number of contracts held at it.
+type rung struct {
+	strike float64
+	qty    int
+}
+
+// Groups legs by strike in ascending order.
+func ladder(strikes []float64, qtys []int) []rung {
+	var rs []rung
+	for i, k := range strikes {
+		found := false
+		for j := range rs {
+			if rs[j].strike == k {
+				rs[j].qty += qtys[i]
+				found = true
+			}
+		}
+		if !found {
+			rs = append(rs, rung{k, qtys[i]})
+		}
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].strike < rs[j].strike })
+	return rs
+}
+
+func (ps Puts) ladder() []rung {
+	ks, qs := make([]float64, len(ps)), make([]int, len(ps))
+	for i, p := range ps {
+		ks[i], qs[i] = p.Strike, p.Qty()
+	}
+	return ladder(ks, qs)
+}
+
+func (cs Calls) ladder() []rung {
+	ks, qs := make([]float64, len(cs)), make([]int, len(cs))
+	for i, c := range cs {
+		ks[i], qs[i] = c.Strike, c.Qty()
+	}
+	return ladder(ks, qs)
+}
+
+// True when wings hold equal quantities at a lower and an upper strike around a body holding twice
+// as many contracts at a single strike.
+func butterfly(wings, body []rung) bool {
+	return len(wings) == 2 && len(body) == 1 &&
+		wings[0].qty == wings[1].qty &&
+		body[0].qty == 2*wings[0].qty &&
+		wings[0].strike < body[0].strike &&
+		body[0].strike < wings[1].strike
+}
+
+// Returns the long and short strikes of legs held at exactly one long and one short strike in unequal
+// quantities. Ratio spreads are net short contracts, backspreads net long.
+func ratio(long, short []rung) (l rung, s rung, ok bool) {
+	if len(long) != 1 || len(short) != 1 {
+		return l, s, false
+	}
+	l, s = long[0], short[0]
+	return l, s, l.qty != s.qty
+}
+
+// Strike, expiration and direction of a single option leg.
+type leg struct {
+	strike     float64
+	expiration time.Time
+	dir        Direction
+}
+
+// Returns the near and far legs of a strategy holding one long and one short option of the same kind
+// expiring on different dates. The strategy is long when the far leg is long.
+func (s *Strategy) timeSpread() (near leg, far leg, ok bool) {
+	if !s.hasNStocks(0) {
+		return near, far, false
+	}
+
+	var l, sh leg
+	if s.hasNPuts(1, 1) && s.hasNCalls(0, 0) {
+		l = leg{s.Lp[0].Strike, s.Lp[0].Expiration, L}
+		sh = leg{s.Sp[0].Strike, s.Sp[0].Expiration, S}
+	} else if s.hasNPuts(0, 0) && s.hasNCalls(1, 1) {
+		l = leg{s.Lc[0].Strike, s.Lc[0].Expiration, L}
+		sh = leg{s.Sc[0].Strike, s.Sc[0].Expiration, S}
+	} else {
+		return near, far, false
+	}
+
+	if l.expiration.Equal(sh.expiration) || !s.evenQuantities() {
+		return near, far, false
+	} else if l.expiration.Before(sh.expiration) {
+		return l, sh, true
+	}
+	return sh, l, true
+}
+
+// True when every option leg expires on the same date. Single-expiry strategy types require this.
+func (s *Strategy) singleExpiration() bool {
+	return len(s.Expirations()) <= 1
+}
+
 func (s *Strategy) hasNCalls(sc, lc int) bool {
 	return len(s.Sc) == sc && len(s.Lc) == lc
 }
@@ -377,7 +662,7 @@ func (s *Strategy) hasNStocks(ss int) bool {
 }
 
 func (s *Strategy) hasAnyOptions() bool {
-	return len(s.Lc) == 1 || len(s.Sc) == 1 || len(s.Sp) == 1 || len(s.Lp) == 1
+	return s.CountOptions() > 0
 }
 
 func (s *Strategy) hasAny(ss, lp, sp, sc, lc bool) bool {
@@ -153,7 +153,7 @@ func TestStrategy(t *testing.T) {
 
 	ps.Property("Strategy.Price == price of underlying assets", prop.ForAll(
 		func(s Strategy) bool {
-			return s.Price() == s.Stocks.Price()+s.PriceOptions()
+			return s.Price() == s.Stocks.Cost()+s.PriceOptions()
 		},
 		GenStrategy(GenTicker())))
 
@@ -338,6 +338,212 @@ func TestStrategyCreation(t *testing.T) {
 		},
 		GenShortNakedPutStrategy(GenTicker())))
 
+	ps.Property("Long put calendar", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenLongPutCalendarStrategy(GenTicker())))
+
+	ps.Property("Short put calendar", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenShortPutCalendarStrategy(GenTicker())))
+
+	ps.Property("Long put diagonal", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenLongPutDiagonalStrategy(GenTicker())))
+
+	ps.Property("Short put diagonal", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenShortPutDiagonalStrategy(GenTicker())))
+
+	ps.Property("Long call calendar", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenLongCallCalendarStrategy(GenTicker())))
+
+	ps.Property("Short call calendar", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenShortCallCalendarStrategy(GenTicker())))
+
+	ps.Property("Long call diagonal", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenLongCallDiagonalStrategy(GenTicker())))
+
+	ps.Property("Short call diagonal", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenShortCallDiagonalStrategy(GenTicker())))
+
+	ps.Property("Call ratio spread", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenCallRatioSpreadStrategy(GenTicker())))
+
+	ps.Property("Put ratio spread", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenPutRatioSpreadStrategy(GenTicker())))
+
+	ps.Property("Call backspread", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenCallBackspreadStrategy(GenTicker())))
+
+	ps.Property("Put backspread", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenPutBackspreadStrategy(GenTicker())))
+
+	ps.Property("Ratio with equal quantities is a spread", prop.ForAll(
+		func(s Strategy) bool {
+			for i := range s.Sc {
+				s.Sc[i].Quantity = s.Lc[0].Quantity
+			}
+			for i := range s.Sp {
+				s.Sp[i].Quantity = s.Lp[0].Quantity
+			}
+			t, _ := s.CheckKind()
+			return t == Spread
+		},
+		gen.OneGenOf(
+			GenCallRatioSpreadStrategy(GenTicker()),
+			GenPutRatioSpreadStrategy(GenTicker()))))
+
+	ps.Property("Ratio with inverted strikes is custom", prop.ForAll(
+		func(s Strategy) bool {
+			if len(s.Lc) > 0 {
+				s.Lc[0].Strike, s.Sc[0].Strike = s.Sc[0].Strike, s.Lc[0].Strike
+			} else {
+				s.Lp[0].Strike, s.Sp[0].Strike = s.Sp[0].Strike, s.Lp[0].Strike
+			}
+			t, _ := s.CheckKind()
+			return t == Custom
+		},
+		gen.OneGenOf(
+			GenCallRatioSpreadStrategy(GenTicker()),
+			GenPutRatioSpreadStrategy(GenTicker()),
+			GenCallBackspreadStrategy(GenTicker()),
+			GenPutBackspreadStrategy(GenTicker()))))
+
+	ps.Property("Ratio split across legs at one strike is still a ratio", prop.ForAll(
+		func(s Strategy) bool {
+			sc := s.Sc[0]
+			sc.Quantity = 1
+			s.Sc = make(Calls, s.Sc[0].Quantity)
+			for i := range s.Sc {
+				s.Sc[i] = sc
+			}
+			return check(s)
+		},
+		GenCallRatioSpreadStrategy(GenTicker())))
+
+	ps.Property("Lots of a strategy keep its type", prop.ForAll(
+		func(s Strategy) bool {
+			return check(s)
+		},
+		GenLotStrategy(gen.OneGenOf(
+			GenShortIronCondorStrategy(GenTicker()),
+			GenLongCallButterflyStrategy(GenTicker()),
+			GenShortCoveredCallStrategy(GenTicker()),
+			GenShortStrangleStrategy(GenTicker()),
+			GenLongPutCalendarStrategy(GenTicker())), gen.IntRange(2, 10))))
+
+	ps.Property("Uneven iron condor is custom", prop.ForAll(
+		func(s Strategy, n int) bool {
+			s.Lc[0].Quantity = n
+			t, _ := s.CheckKind()
+			return t == Custom
+		},
+		GenShortIronCondorStrategy(GenTicker()),
+		gen.IntRange(2, 10)))
+
+	ps.Property("Covered call needs a contract per 100 shares", prop.ForAll(
+		func(s Strategy, n int) bool {
+			s.Sc[0].Quantity = n
+			t, _ := s.CheckKind()
+			return t != CoveredCall
+		},
+		GenShortCoveredCallStrategy(GenTicker()),
+		gen.IntRange(2, 10)))
+
+	ps.Property("Butterfly body may be a single leg", prop.ForAll(
+		func(s Strategy) bool {
+			body := s.Sc[0]
+			body.Quantity = 2
+			s.Sc = Calls{body}
+			return check(s)
+		},
+		GenLongCallButterflyStrategy(GenTicker())))
+
+	ps.Property("CheckKind is deterministic", prop.ForAll(
+		func(s Strategy) bool {
+			t, d := s.CheckKind()
+			for i := 0; i < 20; i++ {
+				if t2, d2 := s.CheckKind(); t2 != t || d2 != d {
+					return false
+				}
+			}
+			return true
+		},
+		GenStrategy(GenTicker())))
+
+	ps.Property("CheckAll leads with CheckKind and includes the generated type", prop.ForAll(
+		func(s Strategy) bool {
+			ms := s.CheckAll()
+			t, d := s.CheckKind()
+			if ms[0].Type != t || ms[0].Dir != d {
+				return false
+			}
+			for _, m := range ms {
+				if m.Type == s.Type && m.Dir == s.Dir {
+					return true
+				}
+			}
+			return false
+		},
+		GenStrategy(GenTicker())))
+
+	ps.Property("Stock with several options is not naked stock", prop.ForAll(
+		func(s Strategy, st Stock) bool {
+			s.Stocks = Stocks{st}
+			t, _ := s.CheckKind()
+			return t != NakedStock
+		},
+		GenShortStrangleStrategy(GenTicker()),
+		GenStock(GenTicker())))
+
+	ps.Property("Mixed expiration strangle is custom", prop.ForAll(
+		func(s Strategy) bool {
+			s.Sc[0].Expiration = s.Sp[0].Expiration.AddDate(0, 0, 7)
+			t, _ := s.CheckKind()
+			return t == Custom
+		},
+		GenShortStrangleStrategy(GenTicker())))
+
+	ps.Property("Mixed expiration iron condor is custom", prop.ForAll(
+		func(s Strategy) bool {
+			s.Lc[0].Expiration = s.Lc[0].Expiration.AddDate(0, 0, 28)
+			t, _ := s.CheckKind()
+			return t == Custom
+		},
+		GenShortIronCondorStrategy(GenTicker())))
+
 	ps.Property("Long custom", prop.ForAll(
 		func(s Strategy) bool {
 			return check(s)
@@ -0,0 +1,255 @@
+package importer
+
+import (
+	"encoding/xml"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ibkrTimeLayouts = []string{"20060102;150405", "20060102 150405", "2006-01-02;15:04:05", "2006-01-02, 15:04:05",
+	"20060102", "2006-01-02"}
+
+type ibkrResponse struct {
+	Statements []ibkrStatement `xml:"FlexStatements>FlexStatement"`
+}
+
+type ibkrStatement struct {
+	Trades    []ibkrRecord `xml:"Trades>Trade"`
+	Positions []ibkrRecord `xml:"OpenPositions>OpenPosition"`
+	EAE       []ibkrRecord `xml:"OptionEAE>OptionEAE"`
+}
+
+// Attributes shared by the rows of the sections read. Rows leave out the attributes their section lacks.
+type ibkrRecord struct {
+	AssetCategory   string `xml:"assetCategory,attr"`
+	Symbol          string `xml:"symbol,attr"`
+	Underlying      string `xml:"underlyingSymbol,attr"`
+	FXRateToBase    string `xml:"fxRateToBase,attr"`
+	Multiplier      string `xml:"multiplier,attr"`
+	Strike          string `xml:"strike,attr"`
+	Expiry          string `xml:"expiry,attr"`
+	PutCall         string `xml:"putCall,attr"`
+	DateTime        string `xml:"dateTime,attr"`
+	TradeDate       string `xml:"tradeDate,attr"`
+	Date            string `xml:"date,attr"`
+	Quantity        string `xml:"quantity,attr"`
+	TradePrice      string `xml:"tradePrice,attr"`
+	Commission      string `xml:"ibCommission,attr"`
+	BuySell         string `xml:"buySell,attr"`
+	OrderID         string `xml:"ibOrderID,attr"`
+	TradeID         string `xml:"tradeID,attr"`
+	TransactionType string `xml:"transactionType,attr"`
+	Notes           string `xml:"notes,attr"`
+	Position        string `xml:"position,attr"`
+	CostBasisPrice  string `xml:"costBasisPrice,attr"`
+	LevelOfDetail   string `xml:"levelOfDetail,attr"`
+}
+
+// Reads an Interactive Brokers Flex Query XML report. Trades come from the Trades section, open positions
+// from OpenPositions and the assignment, exercise and expiry of options from OptionEAE. Only stocks and
+// options are read; rows of other asset categories, such as the CASH rows of currency conversions, are skipped.
+// Fractional share quantities return ErrFractional. Prices and fees are
+// converted to the account's base currency with fxRateToBase. Options closed by OptionEAE records are recorded
+// under one order per event, underlying and day together with the stock trades IBKR books for the delivery,
+// so the shares join the strategy that held the contracts. Times are read as UTC.
+func ReadIBKR(r io.Reader) (Statement, error) {
+	var resp ibkrResponse
+	if e := xml.NewDecoder(r).Decode(&resp); e != nil {
+		return Statement{}, e
+	}
+	if len(resp.Statements) == 0 {
+		return Statement{}, ErrFormat
+	}
+
+	s := Statement{}
+	for _, st := range resp.Statements {
+		for _, t := range st.Trades {
+			// The option side of an assignment, exercise or expiry is read from OptionEAE instead.
+			if !t.read() || t.TransactionType == "BookTrade" && t.AssetCategory == "OPT" {
+				continue
+			}
+			f, e := t.fill()
+			if e != nil {
+				return Statement{}, e
+			}
+			if ev := t.event(); ev != ledger.Traded {
+				f.Event, f.OrderID = ev, t.eaeOrder(ev, f.Time)
+			}
+			s.Fills = append(s.Fills, f)
+		}
+
+		// Stock rows of OptionEAE repeat the deliveries booked in Trades.
+		for _, t := range st.EAE {
+			ev := t.event()
+			if ev == ledger.Traded || t.AssetCategory != "OPT" {
+				continue
+			}
+			f, e := t.fill()
+			if e != nil {
+				return Statement{}, e
+			}
+			f.Price, f.Fees, f.Event, f.OrderID = 0, 0, ev, t.eaeOrder(ev, f.Time)
+			s.Fills = append(s.Fills, f)
+		}
+
+		for _, p := range st.Positions {
+			if !p.read() || p.LevelOfDetail != "" && p.LevelOfDetail != "SUMMARY" {
+				continue
+			}
+			i, e := p.instrument()
+			if e != nil {
+				return Statement{}, e
+			}
+			q, e := quantity(p.Position)
+			if e != nil {
+				return Statement{}, e
+			}
+			price, e := number(p.CostBasisPrice)
+			if e != nil {
+				return Statement{}, e
+			}
+			s.hold(i, q, price*p.fx())
+		}
+	}
+	return s, nil
+}
+
+// Whether the record is of an asset category that is read: stocks and options.
+func (t ibkrRecord) read() bool {
+	return t.AssetCategory == "STK" || t.AssetCategory == "OPT"
+}
+
+func (t ibkrRecord) fill() (ledger.Fill, error) {
+	i, e := t.instrument()
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	at, e := t.time()
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	q, e := quantity(t.Quantity)
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	price, e := number(t.TradePrice)
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	fees, e := number(t.Commission)
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+
+	side := data.Buy
+	if q < 0 || strings.HasPrefix(t.BuySell, "SELL") {
+		side = data.Sell
+	}
+	if q < 0 {
+		q = -q
+	}
+	price = math.Abs(price) * t.fx()
+	order := t.OrderID
+	if order == "" || order == "0" {
+		order = t.TradeID
+	}
+	return ledger.Fill{
+		Time:     at,
+		Asset:    i.Asset(side, q, price),
+		Side:     side,
+		Quantity: q,
+		Price:    price,
+		Fees:     math.Abs(fees) * t.fx(),
+		OrderID:  order}, nil
+}
+
+func (t ibkrRecord) instrument() (ledger.Instrument, error) {
+	if t.AssetCategory == "STK" {
+		return ledger.Instrument{Kind: ledger.StockKind, Ticker: t.Symbol, Multiplier: 1}, nil
+	}
+	if t.AssetCategory != "OPT" {
+		return ledger.Instrument{}, ErrFormat
+	}
+
+	i := ledger.Instrument{Kind: ledger.CallKind, Ticker: t.Underlying, Multiplier: data.ContractMultiplier}
+	if t.PutCall == "P" {
+		i.Kind = ledger.PutKind
+	}
+	exp, e := ibkrTime(t.Expiry)
+	if e != nil {
+		return i, e
+	}
+	i.Expiration = data.ExpiresOn(exp.Year(), exp.Month(), exp.Day())
+	if i.Strike, e = number(t.Strike); e != nil {
+		return i, e
+	}
+	if t.Multiplier != "" {
+		if i.Multiplier, e = number(t.Multiplier); e != nil {
+			return i, e
+		}
+	}
+	return i, nil
+}
+
+// Execution time, falling back to the trade or report date.
+func (t ibkrRecord) time() (time.Time, error) {
+	for _, s := range []string{t.DateTime, t.TradeDate, t.Date} {
+		if s != "" {
+			return ibkrTime(s)
+		}
+	}
+	return time.Time{}, ErrFormat
+}
+
+// Exchange rate to the base currency. Missing rates are one.
+func (t ibkrRecord) fx() float64 {
+	if v, e := strconv.ParseFloat(t.FXRateToBase, 64); e == nil && v > 0 {
+		return v
+	}
+	return 1
+}
+
+// Lifecycle event of an OptionEAE record, or of a stock trade booked by one as marked in its notes.
+func (t ibkrRecord) event() ledger.Event {
+	switch t.TransactionType {
+	case "Assignment":
+		return ledger.Assigned
+	case "Exercise":
+		return ledger.Exercised
+	case "Expiration":
+		return ledger.Expired
+	}
+	for _, n := range strings.Split(t.Notes, ";") {
+		switch n {
+		case "A":
+			return ledger.Assigned
+		case "Ex":
+			return ledger.Exercised
+		}
+	}
+	return ledger.Traded
+}
+
+func (t ibkrRecord) eaeOrder(ev ledger.Event, at time.Time) string {
+	ticker := t.Underlying
+	if ticker == "" {
+		ticker = t.Symbol
+	}
+	return ev.String() + " " + ticker + " " + at.Format("2006-01-02")
+}
+
+func ibkrTime(s string) (time.Time, error) {
+	var e error
+	for _, l := range ibkrTimeLayouts {
+		var t time.Time
+		if t, e = time.Parse(l, s); e == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, e
+}
@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"fmt"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Attributes naming an instrument as a Flex Query row does.
+func ibkrInstrument(i ledger.Instrument) string {
+	if i.Kind == ledger.StockKind {
+		return fmt.Sprintf(`assetCategory="STK" symbol="%s" multiplier="1"`, i.Ticker)
+	}
+	pc := "C"
+	if i.Kind == ledger.PutKind {
+		pc = "P"
+	}
+	return fmt.Sprintf(`assetCategory="OPT" symbol="%s  %s%s" underlyingSymbol="%s" multiplier="%g" strike="%g" expiry="%s" putCall="%s"`,
+		i.Ticker, i.Expiration.Format("060102"), pc, i.Ticker, i.Multiplier, i.Strike, i.Expiration.Format("20060102"), pc)
+}
+
+// Writes the fills and the positions they leave open as a Flex Query report in a currency worth fx of the base.
+func writeIBKR(fs []ledger.Fill, eae string, fx float64) string {
+	var b strings.Builder
+	b.WriteString(`<FlexQueryResponse queryName="all" type="AF"><FlexStatements count="1">`)
+	b.WriteString(`<FlexStatement accountId="U1" fromDate="20190101" toDate="20191231"><Trades>`)
+	for _, f := range fs {
+		i, _ := ledger.InstrumentOf(f.Asset)
+		q, side := f.Quantity, "BUY"
+		if f.Side == data.Sell {
+			q, side = -q, "SELL"
+		}
+		fmt.Fprintf(&b, `<Trade accountId="U1" currency="EUR" fxRateToBase="%g" %s dateTime="%s" tradeDate="%s" `+
+			`quantity="%d" tradePrice="%g" ibCommission="%g" buySell="%s" ibOrderID="%s" transactionType="ExchTrade" notes="%s"/>`,
+			fx, ibkrInstrument(i), f.Time.Format("20060102;150405"), f.Time.Format("20060102"),
+			q, f.Price/fx, -f.Fees/fx, side, f.OrderID, ibkrNotes(f.Event))
+	}
+	b.WriteString(`</Trades><OpenPositions>`)
+	l := ledger.Ledger{}
+	l.Add(fs...)
+	for _, p := range l.Positions(ledger.Epoch.AddDate(1, 0, 0)) {
+		fmt.Fprintf(&b, `<OpenPosition currency="EUR" fxRateToBase="%g" %s position="%d" costBasisPrice="%g" levelOfDetail="SUMMARY"/>`,
+			fx, ibkrInstrument(p.Instrument), p.Quantity, p.Price/fx)
+		fmt.Fprintf(&b, `<OpenPosition %s position="%d" levelOfDetail="LOT"/>`, ibkrInstrument(p.Instrument), p.Quantity)
+	}
+	b.WriteString(`</OpenPositions><OptionEAE>` + eae + `</OptionEAE></FlexStatement></FlexStatements></FlexQueryResponse>`)
+	return b.String()
+}
+
+func ibkrNotes(e ledger.Event) string {
+	switch e {
+	case ledger.Assigned:
+		return "A;P"
+	case ledger.Exercised:
+		return "Ex"
+	}
+	return "P"
+}
+
+func TestIBKR(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6*math.Max(1, math.Abs(a)) }
+
+	ps.Property("Positions are grouped per ticker and classified", prop.ForAll(
+		func(fs []ledger.Fill, fx float64) bool {
+			s, e := ReadIBKR(strings.NewReader(writeIBKR(fs, "", fx)))
+			if e != nil || len(s.Stocks)+len(s.Puts)+len(s.Calls) == 0 {
+				return false
+			}
+			got, e := s.Strategies()
+			want, err := expected(fs)
+			return e == nil && err == nil && sameTypes(got, want)
+		},
+		genFills(), gen.Float64Range(0.5, 2)))
+
+	ps.Property("Trades are converted to the base currency", prop.ForAll(
+		func(fs []ledger.Fill, fx float64) bool {
+			s, e := ReadIBKR(strings.NewReader(writeIBKR(fs, "", fx)))
+			if e != nil || len(s.Fills) != len(fs) {
+				return false
+			}
+			for k, f := range s.Fills {
+				want, _ := ledger.InstrumentOf(fs[k].Asset)
+				got, _ := ledger.InstrumentOf(f.Asset)
+				if got != want || f.Side != fs[k].Side || f.Quantity != fs[k].Quantity || f.OrderID != fs[k].OrderID ||
+					!near(f.Price, fs[k].Price) || !near(f.Fees, fs[k].Fees) || !f.Time.Equal(fs[k].Time) {
+					return false
+				}
+			}
+			return true
+		},
+		genFills(), gen.Float64Range(0.5, 2)))
+
+	ps.Property("Assigned puts deliver stock to the strategy", prop.ForAll(
+		func(fs []ledger.Fill) bool {
+			p := fs[0].Asset.(data.Put)
+			at := data.Expiration
+			i, _ := ledger.InstrumentOf(p)
+			delivery := ledger.Fill{
+				Time:     at,
+				Asset:    data.Stock{Ticker: "AAA"},
+				Side:     data.Buy,
+				Quantity: 100 * fs[0].Quantity,
+				Price:    p.Strike,
+				OrderID:  "0",
+				Event:    ledger.Assigned}
+			eae := fmt.Sprintf(`<OptionEAE %s date="%s" transactionType="Assignment" quantity="%d" tradePrice="0"/>`+
+				`<OptionEAE assetCategory="STK" symbol="AAA" date="%s" transactionType="Buy" quantity="%d"/>`,
+				ibkrInstrument(i), at.Format("20060102"), fs[0].Quantity, at.Format("20060102"), 100*fs[0].Quantity)
+			s, e := ReadIBKR(strings.NewReader(writeIBKR(append(fs, delivery), eae, 1)))
+			if e != nil || len(s.Fills) != 3 || s.Fills[2].Event != ledger.Assigned || s.Fills[1].Event != ledger.Assigned {
+				return false
+			}
+			l := ledger.Ledger{}
+			if l.Add(s.Fills...) != nil {
+				return false
+			}
+			r, e := l.PnL(at, ledger.Quotes{{Kind: ledger.StockKind, Ticker: "AAA", Multiplier: 1}: p.Strike})
+			return e == nil && len(r.Strategies) == 1 && r.Strategies[0].Type == data.NakedStock &&
+				len(l.Positions(at)) == 1
+		},
+		ledger.GenOpeningFills(data.GenShortNakedPutStrategy(gen.Const("AAA")))))
+
+	ps.Property("Rows of other asset categories are skipped", prop.ForAll(
+		func(fs []ledger.Fill) bool {
+			x := writeIBKR(fs, `<OptionEAE assetCategory="FOP" symbol="ESH0 C3300" date="20200117" transactionType="Expiration" quantity="1"/>`, 1)
+			x = strings.Replace(x, "<Trades>", `<Trades><Trade assetCategory="CASH" symbol="EUR.USD" currency="USD" `+
+				`dateTime="20191015;093000" quantity="-1000" tradePrice="1.1" ibCommission="-2" buySell="SELL"/>`+
+				`<Trade assetCategory="FUT" symbol="ESZ9" multiplier="50" dateTime="20191015;093000" quantity="1" `+
+				`tradePrice="3000" buySell="BUY"/>`, 1)
+			x = strings.Replace(x, "<OpenPositions>", `<OpenPositions><OpenPosition assetCategory="CASH" symbol="EUR" `+
+				`position="1000" costBasisPrice="1.1" levelOfDetail="SUMMARY"/>`+
+				`<OpenPosition assetCategory="BOND" symbol="T 2 11/15/26" position="10000" costBasisPrice="98" levelOfDetail="SUMMARY"/>`, 1)
+			s, e := ReadIBKR(strings.NewReader(x))
+			want, err := ReadIBKR(strings.NewReader(writeIBKR(fs, "", 1)))
+			return e == nil && err == nil && len(s.Fills) == len(fs) && len(s.Stocks) == len(want.Stocks) &&
+				len(s.Puts) == len(want.Puts) && len(s.Calls) == len(want.Calls)
+		},
+		genFills()))
+
+	ps.Property("Fractional shares are rejected", prop.ForAll(
+		func(fs []ledger.Fill, n int, position bool) bool {
+			q := fmt.Sprintf("%d.5", n)
+			row := `<Trades><Trade assetCategory="STK" symbol="AAA" currency="USD" dateTime="20191015;093000" ` +
+				`quantity="` + q + `" tradePrice="10" buySell="BUY" transactionType="ExchTrade"/>`
+			section := "<Trades>"
+			if position {
+				row = `<OpenPositions><OpenPosition assetCategory="STK" symbol="AAA" position="` + q +
+					`" costBasisPrice="10" levelOfDetail="SUMMARY"/>`
+				section = "<OpenPositions>"
+			}
+			_, e := ReadIBKR(strings.NewReader(strings.Replace(writeIBKR(fs, "", 1), section, row, 1)))
+			return e == ErrFractional
+		},
+		genFills(), gen.IntRange(0, 10), gen.Bool()))
+
+	ps.Property("Other files are rejected", prop.ForAll(
+		func(s string) bool {
+			_, e := ReadIBKR(strings.NewReader(s))
+			_, empty := ReadIBKR(strings.NewReader("<FlexQueryResponse/>"))
+			return e != nil && empty == ErrFormat
+		},
+		gen.AlphaString()))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"errors"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrFormat     = errors.New("unrecognized statement format")
+	ErrFractional = errors.New("fractional quantities are not supported")
+)
+
+// Trades and positions read from a broker export.
+type Statement struct {
+	Fills  []ledger.Fill // Trade history in the order it was exported. Legs of one order share an OrderID.
+	Stocks data.Stocks   // Open positions.
+	Puts   data.Puts
+	Calls  data.Calls
+	// Whether the positions are exactly those the fills leave open, so Strategies can group them by order.
+	ByOrder bool
+}
+
+// Groups the open positions by underlying ticker, in ticker order, and classifies each ticker's legs with
+// NewStrategy. When the statement is ByOrder, the positions are grouped as they were traded instead, as
+// Ledger.Strategies does after the last fill.
+func (s Statement) Strategies() ([]data.Strategy, error) {
+	if s.ByOrder {
+		if len(s.Fills) == 0 {
+			return nil, nil
+		}
+		l := ledger.Ledger{}
+		if e := l.Add(s.Fills...); e != nil {
+			return nil, e
+		}
+		fs := l.Fills()
+		return l.Strategies(fs[len(fs)-1].Time)
+	}
+	type legs struct {
+		ss data.Stocks
+		ps data.Puts
+		cs data.Calls
+	}
+	byTicker := make(map[string]*legs)
+	get := func(t string) *legs {
+		if byTicker[t] == nil {
+			byTicker[t] = &legs{}
+		}
+		return byTicker[t]
+	}
+	for _, st := range s.Stocks {
+		l := get(st.Ticker)
+		l.ss = append(l.ss, st)
+	}
+	for _, p := range s.Puts {
+		l := get(p.Underlying.Ticker)
+		l.ps = append(l.ps, p)
+	}
+	for _, c := range s.Calls {
+		l := get(c.Underlying.Ticker)
+		l.cs = append(l.cs, c)
+	}
+
+	tickers := make([]string, 0, len(byTicker))
+	for t := range byTicker {
+		tickers = append(tickers, t)
+	}
+	sort.Strings(tickers)
+
+	var ss []data.Strategy
+	for _, t := range tickers {
+		l := byTicker[t]
+		s, e := data.NewStrategy(l.ss, l.ps, l.cs)
+		if e != nil {
+			return nil, e
+		}
+		ss = append(ss, s)
+	}
+	return ss, nil
+}
+
+// Adds an open position in the instrument to the statement.
+func (s *Statement) hold(i ledger.Instrument, quantity int, price float64) {
+	side := data.Buy
+	if quantity < 0 {
+		side, quantity = data.Sell, -quantity
+	}
+	switch a := i.Asset(side, quantity, price).(type) {
+	case data.Stock:
+		s.Stocks = append(s.Stocks, a)
+	case data.Put:
+		s.Puts = append(s.Puts, a)
+	case data.Call:
+		s.Calls = append(s.Calls, a)
+	}
+}
+
+// Parses a number as brokers print it: with an optional sign, thousands separators, a currency symbol, or in
+// parentheses when negative. Empty fields are zero.
+func number(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	s = strings.Trim(s, "()")
+	s = strings.NewReplacer(",", "", "$", "", "+", "").Replace(s)
+	if s == "" {
+		return 0, nil
+	}
+	v, e := strconv.ParseFloat(s, 64)
+	if negative {
+		v = -v
+	}
+	return v, e
+}
+
+// Parses a whole number of shares or contracts. Fractional quantities, such as fractional shares, return
+// ErrFractional. See number.
+func quantity(s string) (int, error) {
+	v, e := number(s)
+	if e != nil {
+		return 0, e
+	}
+	if v != math.Trunc(v) {
+		return 0, ErrFractional
+	}
+	return int(v), nil
+}
+
+// Maps column names of a header row to their indices.
+func columns(header []string) map[string]int {
+	cs := make(map[string]int)
+	for i, h := range header {
+		cs[strings.TrimSpace(h)] = i
+	}
+	return cs
+}
+
+// Field of a row by column name. Missing columns read as empty.
+func field(row []string, cs map[string]int, name string) string {
+	i, ok := cs[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
@@ -0,0 +1,190 @@
+package importer
+
+import (
+	"encoding/csv"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+var tastyTimeLayouts = []string{"2006-01-02T15:04:05-0700", "2006-01-02T15:04:05Z07:00", "01/02/2006 15:04", "1/2/06 15:04"}
+
+const tastyExpirationLayout = "1/2/06"
+
+// Reads a tastytrade transaction history CSV export. Trade and Receive Deliver rows for equities and equity
+// options become fills, in time order, with the legs of one order sharing its order number; every other row,
+// including cash movements and other instrument types, is skipped. Expirations, assignments and exercises
+// close whatever side of the contracts is held. The statement's positions are those the fills leave open, and
+// it is ByOrder: its Strategies classifies the legs of each order together.
+func ReadTastytrade(r io.Reader) (Statement, error) {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+	rows, e := c.ReadAll()
+	if e != nil {
+		return Statement{}, e
+	}
+	if len(rows) == 0 {
+		return Statement{}, ErrFormat
+	}
+	cs := columns(rows[0])
+	for _, name := range []string{"Date", "Type", "Action", "Instrument Type", "Quantity"} {
+		if _, ok := cs[name]; !ok {
+			return Statement{}, ErrFormat
+		}
+	}
+
+	// Exports list the newest transactions first.
+	body := rows[1:]
+	times := make([]time.Time, len(body))
+	for k, row := range body {
+		if times[k], e = tastyTime(field(row, cs, "Date")); e != nil {
+			return Statement{}, e
+		}
+	}
+	idx := make([]int, len(body))
+	for k := range idx {
+		idx[k] = len(body) - 1 - k
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return times[idx[a]].Before(times[idx[b]]) })
+
+	s := Statement{ByOrder: true}
+	held := make(map[ledger.Instrument]int)
+	for _, k := range idx {
+		row := body[k]
+		typ := field(row, cs, "Type")
+		if typ != "Trade" && typ != "Receive Deliver" {
+			continue
+		}
+		i, ok, e := tastyInstrument(row, cs)
+		if e != nil {
+			return Statement{}, e
+		}
+		if !ok {
+			continue
+		}
+		f, e := tastyFill(row, cs, i, times[k], held[i])
+		if e != nil {
+			return Statement{}, e
+		}
+		// Contracts leaving the account and the shares delivered for them share a day but no order number.
+		if f.OrderID == "" && typ == "Receive Deliver" {
+			f.OrderID = typ + " " + i.Ticker + " " + f.Time.Format("2006-01-02")
+		}
+		if f.Side == data.Buy {
+			held[i] += f.Quantity
+		} else {
+			held[i] -= f.Quantity
+		}
+		s.Fills = append(s.Fills, f)
+	}
+
+	l := ledger.Ledger{}
+	if e := l.Add(s.Fills...); e != nil {
+		return Statement{}, e
+	}
+	if len(s.Fills) > 0 {
+		s.Stocks, s.Puts, s.Calls = l.Assets(s.Fills[len(s.Fills)-1].Time)
+	}
+	return s, nil
+}
+
+func tastyFill(row []string, cs map[string]int, i ledger.Instrument, at time.Time, held int) (ledger.Fill, error) {
+	q, e := quantity(field(row, cs, "Quantity"))
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	avg, e := number(field(row, cs, "Average Price"))
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	commissions, e := number(field(row, cs, "Commissions"))
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	fees, e := number(field(row, cs, "Fees"))
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+
+	if q < 0 {
+		q = -q
+	}
+	f := ledger.Fill{
+		Time:     at,
+		Quantity: q,
+		Price:    math.Abs(avg) / i.Multiplier,
+		Fees:     math.Abs(commissions) + math.Abs(fees),
+		OrderID:  field(row, cs, "Order #")}
+
+	// Lifecycle rows carry no action and close the side held.
+	action := field(row, cs, "Action")
+	switch {
+	case strings.HasPrefix(action, "BUY"):
+		f.Side = data.Buy
+	case strings.HasPrefix(action, "SELL"):
+		f.Side = data.Sell
+	case held < 0:
+		f.Side = data.Buy
+	default:
+		f.Side = data.Sell
+	}
+	switch strings.ToLower(field(row, cs, "Sub Type")) {
+	case "expiration":
+		f.Event, f.Price = ledger.Expired, 0
+	case "assignment":
+		f.Event = ledger.Assigned
+	case "exercise":
+		f.Event = ledger.Exercised
+	}
+	f.Asset = i.Asset(f.Side, f.Quantity, f.Price)
+	return f, nil
+}
+
+// Reads the instrument of a row. Rows for anything but equities and equity options are not ok.
+func tastyInstrument(row []string, cs map[string]int) (ledger.Instrument, bool, error) {
+	ticker := field(row, cs, "Underlying Symbol")
+	if ticker == "" {
+		ticker = field(row, cs, "Symbol")
+	}
+	typ := field(row, cs, "Instrument Type")
+	if typ == "Equity" {
+		return ledger.Instrument{Kind: ledger.StockKind, Ticker: ticker, Multiplier: 1}, true, nil
+	}
+	if typ != "Equity Option" {
+		return ledger.Instrument{}, false, nil
+	}
+
+	i := ledger.Instrument{Kind: ledger.CallKind, Ticker: ticker, Multiplier: data.ContractMultiplier}
+	if strings.HasPrefix(strings.ToUpper(field(row, cs, "Call or Put")), "P") {
+		i.Kind = ledger.PutKind
+	}
+	d, e := time.Parse(tastyExpirationLayout, field(row, cs, "Expiration Date"))
+	if e != nil {
+		return i, false, e
+	}
+	i.Expiration = data.ExpiresOn(d.Year(), d.Month(), d.Day())
+	if i.Strike, e = number(field(row, cs, "Strike Price")); e != nil {
+		return i, false, e
+	}
+	if m := field(row, cs, "Multiplier"); m != "" {
+		if i.Multiplier, e = number(m); e != nil {
+			return i, false, e
+		}
+	}
+	return i, true, nil
+}
+
+func tastyTime(s string) (time.Time, error) {
+	var e error
+	for _, l := range tastyTimeLayouts {
+		var t time.Time
+		if t, e = time.Parse(l, s); e == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, e
+}
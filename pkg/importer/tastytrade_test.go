@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"fmt"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"os"
+	"strings"
+	"testing"
+)
+
+const tastyHeader = "Date,Type,Sub Type,Action,Symbol,Instrument Type,Description,Value,Quantity,Average Price," +
+	"Commissions,Fees,Multiplier,Root Symbol,Underlying Symbol,Expiration Date,Strike Price,Call or Put,Order #,Currency\n"
+
+// A transaction row for a fill as tastytrade exports it.
+func tastyRow(f ledger.Fill, typ, sub string) string {
+	i, _ := ledger.InstrumentOf(f.Asset)
+	action := "BUY_TO_OPEN"
+	avg := -f.Price * i.Multiplier
+	if f.Side == data.Sell {
+		action, avg = "SELL_TO_OPEN", -avg
+	}
+	if typ != "Trade" && sub != "Buy to Open" {
+		action = ""
+	}
+	kind, exp, strike, pc := "Equity", "", "", ""
+	if i.Kind != ledger.StockKind {
+		kind, exp, strike, pc = "Equity Option", i.Expiration.Format("1/2/06"), fmt.Sprintf("%g", i.Strike),
+			strings.ToUpper(i.Kind.String())
+	}
+	return fmt.Sprintf("%s,%s,%s,%s,%s,%s,desc,%.4f,%d,%.4f,%.2f,%.2f,%g,%s,%s,%s,%s,%s,%s,USD\n",
+		f.Time.Format("2006-01-02T15:04:05-0700"), typ, sub, action, i.Ticker, kind, avg*float64(f.Quantity),
+		f.Quantity, avg, -f.Fees/2, -f.Fees/2, i.Multiplier, i.Ticker, i.Ticker, exp, strike, pc, f.OrderID)
+}
+
+// Writes fills as a tastytrade transaction history, newest first, with a cash movement mixed in.
+func writeTastytrade(fs []ledger.Fill) string {
+	var b strings.Builder
+	b.WriteString(tastyHeader)
+	for k := len(fs) - 1; k >= 0; k-- {
+		b.WriteString(tastyRow(fs[k], "Trade", ""))
+		if k == len(fs)/2 {
+			b.WriteString(fs[k].Time.Format("2006-01-02T15:04:05-0700") +
+				",Money Movement,Balance Adjustment,,,,Regulatory fee,-0.01,0,,,,,,,,,,,USD\n")
+		}
+	}
+	return b.String()
+}
+
+func TestTastytrade(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	ps.Property("Legs filled under one order are classified together", prop.ForAll(
+		func(a, b []ledger.Fill) bool {
+			fs := append(a, b...)
+			s, e := ReadTastytrade(strings.NewReader(writeTastytrade(fs)))
+			if e != nil || len(s.Fills) != len(fs) {
+				return false
+			}
+			want := ledger.Ledger{}
+			if want.Add(fs...) != nil {
+				return false
+			}
+			at := ledger.Epoch.AddDate(1, 0, 0)
+			got, e := s.Strategies()
+			exp, err := want.Strategies(at)
+			if e != nil || err != nil || len(got) != 2 || len(exp) != 2 {
+				return false
+			}
+			for k := range got {
+				if got[k].Type != exp[k].Type || got[k].Dir != exp[k].Dir || got[k].Type == data.Custom {
+					return false
+				}
+			}
+			return s.ByOrder && len(s.Stocks)+len(s.Puts)+len(s.Calls) == len(want.Positions(at))
+		},
+		ledger.GenOpeningFills(data.GenShortIronCondorStrategy(gen.Const("AAA"))),
+		ledger.GenOpeningFills(genStrategies("AAA"))))
+
+	ps.Property("Expired contracts are closed", prop.ForAll(
+		func(fs []ledger.Fill) bool {
+			at := data.Expiration
+			expired := fs[0]
+			expired.Time, expired.OrderID = at, ""
+			csv := tastyRow(expired, "Receive Deliver", "Expiration") + writeTastytrade(fs)[len(tastyHeader):]
+			s, e := ReadTastytrade(strings.NewReader(tastyHeader + csv))
+			if e != nil || len(s.Fills) != 2 {
+				return false
+			}
+			f := s.Fills[1]
+			return f.Event == ledger.Expired && f.Side == data.Buy && f.Price == 0 && len(s.Puts) == 0
+		},
+		ledger.GenOpeningFills(data.GenShortNakedPutStrategy(gen.Const("AAA")))))
+
+	ps.Property("Assigned puts deliver stock to the strategy", prop.ForAll(
+		func(fs []ledger.Fill) bool {
+			at := data.Expiration
+			p := fs[0].Asset.(data.Put)
+			assigned := fs[0]
+			assigned.Time, assigned.OrderID, assigned.Price = at, "", 0
+			delivered := ledger.Fill{Time: at, Asset: data.Stock{Ticker: "AAA"}, Side: data.Buy,
+				Quantity: 100 * fs[0].Quantity, Price: p.Strike}
+			csv := tastyRow(delivered, "Receive Deliver", "Buy to Open") +
+				tastyRow(assigned, "Receive Deliver", "Assignment") + writeTastytrade(fs)[len(tastyHeader):]
+			s, e := ReadTastytrade(strings.NewReader(tastyHeader + csv))
+			if e != nil || len(s.Fills) != 3 || s.Fills[1].Event != ledger.Assigned {
+				return false
+			}
+			ss, e := s.Strategies()
+			return e == nil && len(ss) == 1 && ss[0].Type == data.NakedStock && len(s.Stocks) == 1
+		},
+		ledger.GenOpeningFills(data.GenShortNakedPutStrategy(gen.Const("AAA")))))
+
+	ps.Property("Other files are rejected", prop.ForAll(
+		func(s string) bool {
+			_, e := ReadTastytrade(strings.NewReader(s))
+			return e != nil
+		},
+		gen.AlphaString()))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
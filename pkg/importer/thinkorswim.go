@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"io"
+	"strings"
+	"time"
+)
+
+// Sections of a thinkorswim account statement that are read. Every other section is skipped.
+const (
+	tosTrades   = "Account Trade History"
+	tosEquities = "Equities"
+	tosOptions  = "Options"
+)
+
+const (
+	tosTimeLayout       = "1/2/06 15:04:05"
+	tosExpirationLayout = "2 Jan 06"
+)
+
+// Reads a thinkorswim (Schwab) "Account Statement" CSV export. Trades come from the Account Trade History
+// section, where the legs of a spread follow its first row without an execution time and are given one
+// OrderID, which holds the execution time so that orders stay apart when several statements are read into
+// one ledger. Open positions come from the Equities and Options sections, skipping their totals and rows with no
+// quantity. Options are read from their OCC-style Option Code where the row has one. Execution times are read
+// as UTC.
+func ReadThinkorswim(r io.Reader) (Statement, error) {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+	c.LazyQuotes = true
+	rows, e := c.ReadAll()
+	if e != nil {
+		return Statement{}, e
+	}
+
+	// Blank lines are skipped by the reader, so a section ends where a row holding only a title starts the next.
+	s := Statement{}
+	found := false
+	var section, order string
+	var cs map[string]int
+	var at time.Time
+	for _, row := range rows {
+		if t, ok := title(row); ok {
+			section, cs = t, nil
+			found = found || t == tosTrades || t == tosEquities || t == tosOptions
+			continue
+		}
+		if cs == nil {
+			cs = columns(row)
+			continue
+		}
+
+		switch section {
+		case tosTrades:
+			if t := field(row, cs, "Exec Time"); t != "" {
+				if at, e = time.Parse(tosTimeLayout, t); e != nil {
+					return Statement{}, e
+				}
+				order = fmt.Sprintf("tos-%s-%d", at.Format("20060102T150405"), len(s.Fills)+1)
+			}
+			f, e := tosFill(row, cs, at, order)
+			if e != nil {
+				return Statement{}, e
+			}
+			s.Fills = append(s.Fills, f)
+		case tosEquities, tosOptions:
+			if strings.Contains(strings.ToUpper(row[0]), "TOTAL") {
+				continue
+			}
+			q, e := quantity(field(row, cs, "Qty"))
+			if e != nil {
+				return Statement{}, e
+			}
+			if q == 0 {
+				continue
+			}
+			i, e := tosInstrument(row, cs)
+			if e != nil {
+				return Statement{}, e
+			}
+			p, e := number(field(row, cs, "Trade Price"))
+			if e != nil {
+				return Statement{}, e
+			}
+			s.hold(i, q, p)
+		}
+	}
+	if !found {
+		return Statement{}, ErrFormat
+	}
+	return s, nil
+}
+
+// Returns the text of a row holding a single field, as section titles do.
+func title(row []string) (string, bool) {
+	var fs []string
+	for _, f := range row {
+		if f = strings.TrimSpace(f); f != "" {
+			fs = append(fs, f)
+		}
+	}
+	if len(fs) != 1 {
+		return "", false
+	}
+	return fs[0], true
+}
+
+func tosFill(row []string, cs map[string]int, at time.Time, order string) (ledger.Fill, error) {
+	i, e := tosInstrument(row, cs)
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	q, e := quantity(field(row, cs, "Qty"))
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	p, e := number(field(row, cs, "Price"))
+	if e != nil {
+		return ledger.Fill{}, e
+	}
+	side := data.Buy
+	if field(row, cs, "Side") == "SELL" {
+		side = data.Sell
+	}
+	if q < 0 {
+		q = -q
+	}
+	return ledger.Fill{Time: at, Asset: i.Asset(side, q, p), Side: side, Quantity: q, Price: p, OrderID: order}, nil
+}
+
+// Reads the instrument of a trade or position row: an option from its Option Code, such as ".SPY200117C340",
+// or from its Exp, Strike and Type columns. Stock rows have no Type column or a Type of STOCK or ETF.
+func tosInstrument(row []string, cs map[string]int) (ledger.Instrument, error) {
+	if code := field(row, cs, "Option Code"); code != "" {
+		a, e := data.ParseOption(code)
+		if e != nil {
+			return ledger.Instrument{}, e
+		}
+		return ledger.InstrumentOf(a)
+	}
+
+	i := ledger.Instrument{Kind: ledger.StockKind, Ticker: field(row, cs, "Symbol"), Multiplier: 1}
+	switch field(row, cs, "Type") {
+	case "PUT":
+		i.Kind = ledger.PutKind
+	case "CALL":
+		i.Kind = ledger.CallKind
+	default:
+		return i, nil
+	}
+
+	// Expirations may carry a trailing note such as "(Weeklys)".
+	exp := strings.Fields(field(row, cs, "Exp"))
+	if len(exp) < 3 {
+		return i, ErrFormat
+	}
+	d, e := time.Parse(tosExpirationLayout, strings.Join(exp[:3], " "))
+	if e != nil {
+		return i, e
+	}
+	if i.Strike, e = number(field(row, cs, "Strike")); e != nil {
+		return i, e
+	}
+	i.Expiration = data.ExpiresOn(d.Year(), d.Month(), d.Day())
+	i.Multiplier = data.ContractMultiplier
+	return i, nil
+}
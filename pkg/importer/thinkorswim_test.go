@@ -0,0 +1,215 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Generates strategies on a ticker spanning stock, single and multiple expiration option legs.
+func genStrategies(ticker string) gopter.Gen {
+	t := gen.Const(ticker)
+	return gen.OneGenOf(
+		data.GenShortIronCondorStrategy(t),
+		data.GenShortCoveredCallStrategy(t),
+		data.GenLongStrangleStrategy(t),
+		data.GenLongPutCalendarStrategy(t),
+		data.GenLongNakedStockStrategy(t))
+}
+
+// Generates the fills opening a strategy on each of two tickers.
+func genFills() gopter.Gen {
+	return gopter.CombineGens(
+		ledger.GenOpeningFills(genStrategies("AAA")),
+		ledger.GenOpeningFills(genStrategies("BBB"))).Map(func(vs []interface{}) []ledger.Fill {
+		return append(vs[0].([]ledger.Fill), vs[1].([]ledger.Fill)...)
+	})
+}
+
+// Classifies the positions the fills leave open per ticker.
+func expected(fs []ledger.Fill) ([]data.Strategy, error) {
+	l := ledger.Ledger{}
+	if e := l.Add(fs...); e != nil {
+		return nil, e
+	}
+	ss, ps, cs := l.Assets(ledger.Epoch.AddDate(1, 0, 0))
+	return Statement{Stocks: ss, Puts: ps, Calls: cs}.Strategies()
+}
+
+func sameTypes(a, b []data.Strategy) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if a[k].Ticker != b[k].Ticker || a[k].Type != b[k].Type || a[k].Dir != b[k].Dir {
+			return false
+		}
+	}
+	return true
+}
+
+// Columns of an instrument as thinkorswim prints them: symbol, expiration, strike and type.
+func tosColumns(i ledger.Instrument) (string, string, string, string) {
+	if i.Kind == ledger.StockKind {
+		return i.Ticker, "", "", "STOCK"
+	}
+	return i.Ticker, strings.ToUpper(i.Expiration.Format("2 Jan 06")), fmt.Sprintf("%g", i.Strike),
+		strings.ToUpper(i.Kind.String())
+}
+
+// Writes the fills and the positions they leave open as a thinkorswim account statement.
+func writeThinkorswim(fs []ledger.Fill) string {
+	var b strings.Builder
+	b.WriteString("Account Statement for 123 since 10/1/19 through 12/31/19\n\nCash Balance\n")
+	b.WriteString("DATE,TIME,TYPE,REF #,DESCRIPTION,Misc Fees,Commissions & Fees,AMOUNT,BALANCE\n")
+	b.WriteString("10/1/19,00:00:00,BAL,,Cash balance at the start of business day,,,,\"1,000.00\"\n\n")
+
+	b.WriteString("Account Trade History\n")
+	b.WriteString(",Exec Time,Spread,Side,Qty,Pos Effect,Symbol,Exp,Strike,Type,Price,Net Price,Order Type\n")
+	for k, f := range fs {
+		at := ""
+		if k == 0 || f.OrderID != fs[k-1].OrderID {
+			at = f.Time.Format("1/2/06 15:04:05")
+		}
+		i, _ := ledger.InstrumentOf(f.Asset)
+		sym, exp, strike, typ := tosColumns(i)
+		side, q := "BUY", fmt.Sprintf("+%d", f.Quantity)
+		if f.Side == data.Sell {
+			side, q = "SELL", fmt.Sprintf("-%d", f.Quantity)
+		}
+		fmt.Fprintf(&b, ",%s,CUSTOM,%s,%s,TO OPEN,%s,%s,%s,%s,%.2f,,LMT\n", at, side, q, sym, exp, strike, typ, f.Price)
+	}
+
+	l := ledger.Ledger{}
+	l.Add(fs...)
+	b.WriteString("\nEquities\nSymbol,Description,Qty,Trade Price,Mark,Mark Value\n")
+	for _, p := range l.Positions(ledger.Epoch.AddDate(1, 0, 0)) {
+		if p.Instrument.Kind == ledger.StockKind {
+			fmt.Fprintf(&b, "%s,COMMON STOCK,%+d,\"%s\",0,0\n", p.Instrument.Ticker, p.Quantity, withCommas(p.Price))
+		}
+	}
+	b.WriteString("OVERALL TOTALS,,,,,50.00\n")
+	b.WriteString("\nOptions\nSymbol,Option Code,Exp,Strike,Type,Qty,Trade Price,Mark,Mark Value\n")
+	for _, p := range l.Positions(ledger.Epoch.AddDate(1, 0, 0)) {
+		if p.Instrument.Kind != ledger.StockKind {
+			sym, exp, strike, typ := tosColumns(p.Instrument)
+			code, _ := data.FormatOption(p.Asset(), data.Dotted)
+			fmt.Fprintf(&b, "%s,%s,%s (Weeklys),%s,%s,%+d,%.2f,0,(0.00)\n", sym, code, exp, strike, typ, p.Quantity,
+				p.Price)
+		}
+	}
+	b.WriteString("OVERALL TOTALS,,,,,,,,50.00\n")
+	b.WriteString("\nProfits and Losses\nSymbol,Description,P/L Open\n")
+	return b.String()
+}
+
+func withCommas(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	if v >= 1000 {
+		s = s[:len(s)-6] + "," + s[len(s)-6:]
+	}
+	return s
+}
+
+// An export with every section thinkorswim writes, totals rows and a closed position.
+func TestThinkorswimStatement(t *testing.T) {
+	f, e := os.Open("testdata/thinkorswim.csv")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer f.Close()
+	s, e := ReadThinkorswim(f)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if len(s.Fills) != 6 || s.Fills[0].OrderID != s.Fills[3].OrderID || s.Fills[3].OrderID == s.Fills[4].OrderID {
+		t.Errorf("fills %+v", s.Fills)
+	}
+	if len(s.Stocks) != 1 || s.Stocks[0].Ticker != "AAPL" || s.Stocks[0].Shares != 100 || len(s.Calls) != 3 ||
+		len(s.Puts) != 2 {
+		t.Fatalf("positions %+v %+v %+v", s.Stocks, s.Puts, s.Calls)
+	}
+	want := data.Put{Underlying: data.Stock{Ticker: "SPY"}, Price: 0.25, Strike: 310,
+		Expiration: data.ExpiresOn(2020, time.January, 17), Side: data.Buy, Quantity: 1, Multiplier: 100}
+	if s.Puts[1] != want {
+		t.Errorf("put read from its option code %+v, want %+v", s.Puts[1], want)
+	}
+
+	ss, e := s.Strategies()
+	if e != nil || len(ss) != 2 || ss[0].Type != data.CoveredCall || ss[1].Type != data.IronCondor ||
+		ss[1].Dir != data.S {
+		t.Errorf("strategies %+v %v", ss, e)
+	}
+}
+
+func TestThinkorswim(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	ps.Property("Positions are grouped per ticker and classified", prop.ForAll(
+		func(fs []ledger.Fill) bool {
+			s, e := ReadThinkorswim(strings.NewReader(writeThinkorswim(fs)))
+			if e != nil {
+				return false
+			}
+			got, e := s.Strategies()
+			want, err := expected(fs)
+			return e == nil && err == nil && sameTypes(got, want)
+		},
+		genFills()))
+
+	ps.Property("Trades keep their legs, prices and orders", prop.ForAll(
+		func(fs []ledger.Fill) bool {
+			s, e := ReadThinkorswim(strings.NewReader(writeThinkorswim(fs)))
+			if e != nil || len(s.Fills) != len(fs) {
+				return false
+			}
+			for k, f := range s.Fills {
+				want, _ := ledger.InstrumentOf(fs[k].Asset)
+				got, _ := ledger.InstrumentOf(f.Asset)
+				sameOrder := k == 0 || (f.OrderID == s.Fills[k-1].OrderID) == (fs[k].OrderID == fs[k-1].OrderID)
+				if got != want || f.Side != fs[k].Side || f.Quantity != fs[k].Quantity || !sameOrder ||
+					math.Abs(f.Price-fs[k].Price) > 0.005 || !f.Time.Equal(fs[k].Time.Truncate(time.Second)) {
+					return false
+				}
+			}
+			l := ledger.Ledger{}
+			return l.Add(s.Fills...) == nil && len(l.Trades()) == 2
+		},
+		genFills()))
+
+	ps.Property("Orders of consecutive statements stay apart", prop.ForAll(
+		func(fs []ledger.Fill) bool {
+			next := make([]ledger.Fill, len(fs))
+			for k, f := range fs {
+				f.Time = f.Time.AddDate(0, 1, 0)
+				next[k] = f
+			}
+			a, e := ReadThinkorswim(strings.NewReader(writeThinkorswim(fs)))
+			b, err := ReadThinkorswim(strings.NewReader(writeThinkorswim(next)))
+			l, one := ledger.Ledger{}, ledger.Ledger{}
+			return e == nil && err == nil && one.Add(a.Fills...) == nil &&
+				l.Add(append(a.Fills, b.Fills...)...) == nil && len(l.Trades()) == 2*len(one.Trades())
+		},
+		genFills()))
+
+	ps.Property("Other files are rejected", prop.ForAll(
+		func(s string) bool {
+			_, e := ReadThinkorswim(bytes.NewBufferString(s))
+			return e != nil
+		},
+		gen.AlphaString()))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
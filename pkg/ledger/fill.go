@@ -0,0 +1,138 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"strconv"
+	"time"
+)
+
+// Kind of instrument a fill trades.
+type Kind int
+
+const (
+	StockKind Kind = iota
+	PutKind   Kind = iota
+	CallKind  Kind = iota
+)
+
+func (k Kind) String() string {
+	return []string{"Stock", "Put", "Call"}[k]
+}
+
+// Identifies a tradable instrument independently of any position held in it.
+type Instrument struct {
+	Kind       Kind
+	Ticker     string
+	Strike     float64
+	Expiration time.Time
+	Multiplier float64
+}
+
+// Returns the instrument a Stock, Put or Call refers to. Prices, sides and quantities are ignored.
+func InstrumentOf(a data.Asset) (Instrument, error) {
+	switch a := a.(type) {
+	case data.Stock:
+		return Instrument{Kind: StockKind, Ticker: a.Ticker, Multiplier: 1}, nil
+	case data.Put:
+		return Instrument{PutKind, a.Underlying.Ticker, a.Strike, a.Expiration.UTC(), a.Mult()}, nil
+	case data.Call:
+		return Instrument{CallKind, a.Underlying.Ticker, a.Strike, a.Expiration.UTC(), a.Mult()}, nil
+	}
+	return Instrument{}, errors.New("unsupported asset type")
+}
+
+// Describes the instrument, e.g. "AAA" for stock or "AAA 2020-01-17 450 Put".
+func (i Instrument) String() string {
+	if i.Kind == StockKind {
+		return i.Ticker
+	}
+	return fmt.Sprintf("%s %s %s %s", i.Ticker, i.Expiration.Format("2006-01-02"),
+		strconv.FormatFloat(i.Strike, 'f', -1, 64), i.Kind)
+}
+
+// Returns an asset in the instrument with an explicit side. Quantity is in shares for stock and contracts
+// for options, and price is per share.
+func (i Instrument) Asset(side data.Side, quantity int, price float64) data.Asset {
+	switch i.Kind {
+	case PutKind:
+		return data.Put{
+			Underlying: data.Stock{Ticker: i.Ticker},
+			Price:      price,
+			Strike:     i.Strike,
+			Expiration: i.Expiration,
+			Side:       side,
+			Quantity:   quantity,
+			Multiplier: i.Multiplier}
+	case CallKind:
+		return data.Call{
+			Underlying: data.Stock{Ticker: i.Ticker},
+			Price:      price,
+			Strike:     i.Strike,
+			Expiration: i.Expiration,
+			Side:       side,
+			Quantity:   quantity,
+			Multiplier: i.Multiplier}
+	}
+	return data.Stock{Ticker: i.Ticker, Price: price, Shares: quantity, Side: side}
+}
+
+// What caused a fill.
+type Event int
+
+const (
+	Traded    Event = iota
+	Exercised Event = iota
+	Assigned  Event = iota
+	Expired   Event = iota
+	Rolled    Event = iota
+)
+
+func (e Event) String() string {
+	return []string{"Traded", "Exercised", "Assigned", "Expired", "Rolled"}[e]
+}
+
+// A single execution. Quantity is in shares for stock and contracts for options, Price is per share and
+// Fees are the total charged for the fill.
+type Fill struct {
+	Time     time.Time
+	Asset    data.Asset
+	Side     data.Side
+	Quantity int
+	Price    float64
+	Fees     float64
+	OrderID  string
+	Event    Event
+}
+
+func (f Fill) validate() error {
+	if f.Asset == nil || f.Asset.Empty() {
+		return errors.New("fill must have an asset")
+	}
+	if f.Side != data.Buy && f.Side != data.Sell {
+		return errors.New("fill side must be Buy or Sell")
+	}
+	if f.Quantity <= 0 {
+		return errors.New("fill quantity must be positive")
+	}
+	if f.Price < 0 {
+		return errors.New("fill price must not be negative")
+	}
+	return nil
+}
+
+// Signed change in position: positive when buying.
+func (f Fill) delta() int {
+	if f.Side == data.Sell {
+		return -f.Quantity
+	}
+	return f.Quantity
+}
+
+// The fills executed for a single order.
+type Trade struct {
+	OrderID string
+	Time    time.Time // Time of the first fill.
+	Fills   []Fill
+}
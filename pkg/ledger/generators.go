@@ -0,0 +1,75 @@
+package ledger
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"reflect"
+	"time"
+)
+
+const MaxFees float64 = 5
+
+// Start of the period over which fills are generated.
+var Epoch = data.Expiration.AddDate(0, -3, 0)
+
+// Generates fill times within a month of Epoch.
+func GenTimes() gopter.Gen {
+	return gen.IntRange(0, 30*24*60).Map(func(m int) time.Time {
+		return Epoch.Add(time.Duration(m) * time.Minute)
+	})
+}
+
+// Generates stock, put or call assets on the ticker.
+func GenAsset(ticker gopter.Gen) gopter.Gen {
+	return gen.OneGenOf(
+		data.GenStock(ticker).Map(func(s data.Stock) data.Asset { return s }),
+		data.GenPut(ticker).Map(func(p data.Put) data.Asset { return p }),
+		data.GenCall(ticker).Map(func(c data.Call) data.Asset { return c }))
+}
+
+// Generates fills in the given asset.
+func GenFill(asset gopter.Gen) gopter.Gen {
+	return gen.Struct(
+		reflect.TypeOf(Fill{}),
+		map[string]gopter.Gen{
+			"Time":     GenTimes(),
+			"Asset":    asset,
+			"Side":     gen.OneConstOf(data.Buy, data.Sell),
+			"Quantity": gen.IntRange(1, 10),
+			"Price":    gen.Float64Range(0, data.MaxOptionPrice),
+			"Fees":     gen.Float64Range(0, MaxFees),
+			"OrderID":  gen.Identifier()})
+}
+
+// Converts every leg of a strategy into a fill opening it at time at under a single order.
+func openingFills(s data.Strategy, at time.Time, order string) []Fill {
+	var fs []Fill
+	add := func(a data.Asset, side data.Side, q int, price float64) {
+		fs = append(fs, Fill{Time: at, Asset: a, Side: side, Quantity: q, Price: math.Abs(price), OrderID: order})
+	}
+	for _, st := range s.Stocks {
+		add(st, st.Dir().Side(), st.Qty(), st.Price)
+	}
+	for _, p := range s.Lp {
+		add(p, data.Buy, p.Qty(), p.Price)
+	}
+	for _, p := range s.Sp {
+		add(p, data.Sell, p.Qty(), p.Price)
+	}
+	for _, c := range s.Lc {
+		add(c, data.Buy, c.Qty(), c.Price)
+	}
+	for _, c := range s.Sc {
+		add(c, data.Sell, c.Qty(), c.Price)
+	}
+	return fs
+}
+
+// Generates the fills that open a generated strategy under one order at a generated time.
+func GenOpeningFills(strategy gopter.Gen) gopter.Gen {
+	return gopter.CombineGens(strategy, GenTimes(), gen.Identifier()).Map(func(vs []interface{}) []Fill {
+		return openingFills(vs[0].(data.Strategy), vs[1].(time.Time), vs[2].(string))
+	})
+}
@@ -0,0 +1,176 @@
+package ledger
+
+import (
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"sort"
+	"time"
+)
+
+// Open position in a single instrument.
+type Position struct {
+	Instrument Instrument
+	Quantity   int       // Signed: negative when short.
+	Price      float64   // Average opening price per share.
+	Opened     time.Time // When the position was opened from flat.
+}
+
+// Returns the position as a Stock, Put or Call with an explicit side.
+func (p Position) Asset() data.Asset {
+	side, q := data.Buy, p.Quantity
+	if q < 0 {
+		side, q = data.Sell, -q
+	}
+	return p.Instrument.Asset(side, q, p.Price)
+}
+
+// History of fills from which positions at any point in time are rebuilt.
+type Ledger struct {
+	fills []Fill
+}
+
+// Records fills. Fills are kept in time order; fills at the same time keep the order they were added in.
+func (l *Ledger) Add(fs ...Fill) error {
+	for _, f := range fs {
+		if e := f.validate(); e != nil {
+			return e
+		}
+		if _, e := InstrumentOf(f.Asset); e != nil {
+			return e
+		}
+	}
+	l.fills = append(l.fills, fs...)
+	sort.SliceStable(l.fills, func(i, j int) bool { return l.fills[i].Time.Before(l.fills[j].Time) })
+	return nil
+}
+
+// Returns every fill in time order.
+func (l *Ledger) Fills() []Fill {
+	return append([]Fill(nil), l.fills...)
+}
+
+// Groups fills into trades by order id, in order of each trade's first fill. Fills without an order id
+// each form their own trade.
+func (l *Ledger) Trades() []Trade {
+	var ts []Trade
+	idx := make(map[string]int)
+	for _, f := range l.fills {
+		if i, ok := idx[f.OrderID]; ok && f.OrderID != "" {
+			ts[i].Fills = append(ts[i].Fills, f)
+			continue
+		}
+		idx[f.OrderID] = len(ts)
+		ts = append(ts, Trade{OrderID: f.OrderID, Time: f.Time, Fills: []Fill{f}})
+	}
+	return ts
+}
+
+// Applies every fill up to and including time at and returns the open positions sorted by instrument.
+func (l *Ledger) Positions(at time.Time) []Position {
+	ps := make(map[Instrument]*Position)
+	for _, f := range l.fills {
+		if f.Time.After(at) {
+			break
+		}
+		i, _ := InstrumentOf(f.Asset)
+		p, ok := ps[i]
+		if !ok {
+			p = &Position{Instrument: i}
+			ps[i] = p
+		}
+		p.apply(f)
+		if p.Quantity == 0 {
+			delete(ps, i)
+		}
+	}
+
+	var open []Position
+	for _, p := range ps {
+		open = append(open, *p)
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].Instrument.less(open[j].Instrument) })
+	return open
+}
+
+// Updates the position with a fill. Fills in the direction of the position open more of it at a new
+// average price; fills against it close it and, past flat, open the opposite side at the fill price.
+func (p *Position) apply(f Fill) {
+	d := f.delta()
+	switch {
+	case p.Quantity == 0:
+		p.Quantity, p.Price, p.Opened = d, f.Price, f.Time
+	case (p.Quantity > 0) == (d > 0):
+		q := abs(p.Quantity) + abs(d)
+		p.Price = (p.Price*float64(abs(p.Quantity)) + f.Price*float64(abs(d))) / float64(q)
+		p.Quantity += d
+	case abs(d) <= abs(p.Quantity):
+		p.Quantity += d
+	default:
+		p.Quantity, p.Price, p.Opened = p.Quantity+d, f.Price, f.Time
+	}
+}
+
+// Returns the open positions at time at as stocks, puts and calls with explicit sides.
+func (l *Ledger) Assets(at time.Time) (data.Stocks, data.Puts, data.Calls) {
+	var ss data.Stocks
+	var ps data.Puts
+	var cs data.Calls
+	for _, p := range l.Positions(at) {
+		switch a := p.Asset().(type) {
+		case data.Stock:
+			ss = append(ss, a)
+		case data.Put:
+			ps = append(ps, a)
+		case data.Call:
+			cs = append(cs, a)
+		}
+	}
+	return ss, ps, cs
+}
+
+// Rebuilds the open positions at time at and groups them into strategies per ticker.
+func (l *Ledger) Portfolio(at time.Time) (data.Portfolio, error) {
+	ss, ps, cs := l.Assets(at)
+	return data.NewPortfolio(ss, ps, cs)
+}
+
+// Rebuilds the open positions at time at grouped as they were traded: the legs opened by one order, and any
+// legs later rolled into them, form one strategy classified by NewStrategy. Unlike Portfolio, legs are not
+// regrouped by Decompose. Strategies are returned in the order they were opened.
+func (l *Ledger) Strategies(at time.Time) ([]data.Strategy, error) {
+	gs, e := l.replay(at)
+	if e != nil {
+		return nil, e
+	}
+	var ss []data.Strategy
+	for _, g := range gs {
+		if !g.Open() {
+			continue
+		}
+		s, e := g.strategy()
+		if e != nil {
+			return nil, e
+		}
+		ss = append(ss, s)
+	}
+	return ss, nil
+}
+
+func (i Instrument) less(o Instrument) bool {
+	if i.Ticker != o.Ticker {
+		return i.Ticker < o.Ticker
+	}
+	if i.Kind != o.Kind {
+		return i.Kind < o.Kind
+	}
+	if !i.Expiration.Equal(o.Expiration) {
+		return i.Expiration.Before(o.Expiration)
+	}
+	return i.Strike < o.Strike
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,117 @@
+package ledger
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLedger(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	asset := GenAsset(gen.Const("AAA"))
+
+	ps.Property("Add rejects invalid fills", prop.ForAll(
+		func(f Fill) bool {
+			l := Ledger{}
+			bad := f
+			bad.Quantity = 0
+			noSide := f
+			noSide.Side = data.Unset
+			return l.Add(bad) != nil && l.Add(noSide) != nil && len(l.Fills()) == 0
+		},
+		GenFill(asset)))
+
+	ps.Property("Positions net every fill per instrument", prop.ForAll(
+		func(fs []Fill) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			net := make(map[Instrument]int)
+			for _, f := range fs {
+				i, _ := InstrumentOf(f.Asset)
+				net[i] += f.delta()
+			}
+			open := l.Positions(Epoch.AddDate(1, 0, 0))
+			n := 0
+			for _, q := range net {
+				if q != 0 {
+					n++
+				}
+			}
+			if len(open) != n {
+				return false
+			}
+			for _, p := range open {
+				if net[p.Instrument] != p.Quantity {
+					return false
+				}
+			}
+			return true
+		},
+		gen.SliceOfN(20, GenFill(asset))))
+
+	ps.Property("Positions ignore fills after the requested time", prop.ForAll(
+		func(fs []Fill) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			return len(l.Positions(Epoch.Add(-time.Minute))) == 0
+		},
+		gen.SliceOfN(5, GenFill(asset))))
+
+	ps.Property("Closing every fill leaves the ledger flat", prop.ForAll(
+		func(fs []Fill) bool {
+			l := Ledger{}
+			for _, f := range fs {
+				c := f
+				c.Time = f.Time.AddDate(0, 2, 0)
+				c.Side = data.Buy
+				if f.Side == data.Buy {
+					c.Side = data.Sell
+				}
+				if l.Add(f, c) != nil {
+					return false
+				}
+			}
+			return len(l.Positions(Epoch.AddDate(0, 1, 1))) > 0 && len(l.Positions(Epoch.AddDate(1, 0, 0))) == 0
+		},
+		gen.SliceOfN(5, GenFill(asset))))
+
+	ps.Property("Average price of added fills", prop.ForAll(
+		func(a, b Fill) bool {
+			b.Asset, b.Side, b.Time = a.Asset, a.Side, a.Time.Add(time.Minute)
+			l := Ledger{}
+			if l.Add(a, b) != nil {
+				return false
+			}
+			p := l.Positions(b.Time)[0]
+			want := (a.Price*float64(a.Quantity) + b.Price*float64(b.Quantity)) / float64(a.Quantity+b.Quantity)
+			return abs(p.Quantity) == a.Quantity+b.Quantity && p.Price-want < 1e-9 && want-p.Price < 1e-9 &&
+				p.Opened.Equal(a.Time)
+		},
+		GenFill(asset), GenFill(asset)))
+
+	ps.Property("Ledger rebuilds the strategy an order opened", prop.ForAll(
+		func(fs []Fill) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			p, e := l.Portfolio(fs[0].Time)
+			ts := l.Trades()
+			return e == nil && len(p.Strategies) == 1 && p.Strategies[0].Type == data.IronCondor &&
+				len(ts) == 1 && len(ts[0].Fills) == 4
+		},
+		GenOpeningFills(data.GenShortIronCondorStrategy(gen.Const("AAA")))))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,193 @@
+package ledger
+
+import (
+	"errors"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"time"
+)
+
+var (
+	ErrNotOption   = errors.New("only puts and calls can be exercised, assigned or expired")
+	ErrNoContracts = errors.New("not enough contracts held on that side")
+	ErrNotClosing  = errors.New("a roll must close held legs and open new ones")
+	ErrDeliverable = errors.New("contracts deliver a fractional number of shares")
+)
+
+// Exercises long contracts at time at: the holder buys, for a call, or sells, for a put, the deliverable shares
+// at the strike. See settle for how the premium paid is carried to the shares.
+func (l *Ledger) Exercise(option data.Asset, contracts int, at time.Time) error {
+	return l.settle(option, contracts, at, Exercised)
+}
+
+// Assigns short contracts at time at: the writer sells, for a call, or buys, for a put, the deliverable shares
+// at the strike. See settle for how the premium received is carried to the shares.
+func (l *Ledger) Assign(option data.Asset, contracts int, at time.Time) error {
+	return l.settle(option, contracts, at, Assigned)
+}
+
+// Records the fills of an exercise or assignment under one order so that the shares delivered join the
+// strategy that held the contracts. As for tax, the premium is not a gain of its own: the contracts close at
+// their average opening price and the shares trade at the strike plus the premium for a call, or less it for a
+// put, which adds a call's premium to the cost of shares bought or the proceeds of shares sold and takes a
+// put's from them.
+func (l *Ledger) settle(option data.Asset, contracts int, at time.Time, ev Event) error {
+	i, e := InstrumentOf(option)
+	if e != nil {
+		return e
+	}
+	if i.Kind == StockKind {
+		return ErrNotOption
+	}
+
+	held, premium := 0, 0.0
+	for _, p := range l.Positions(at) {
+		if p.Instrument == i {
+			held, premium = p.Quantity, p.Price
+		}
+	}
+	long := ev == Exercised
+	if contracts <= 0 || (long && held < contracts) || (!long && -held < contracts) {
+		return ErrNoContracts
+	}
+
+	// Closing a long contract sells it; the shares move the way the contract gives its holder the right to.
+	side, shares := data.Sell, data.Buy
+	if !long {
+		side = data.Buy
+	}
+	if (i.Kind == CallKind) != long {
+		shares = data.Sell
+	}
+
+	n := float64(contracts) * i.Multiplier
+	if math.Abs(n-math.Round(n)) > 1e-9 {
+		return ErrDeliverable
+	}
+	price := i.Strike + premium
+	if i.Kind == PutKind {
+		price = math.Max(0, i.Strike-premium)
+	}
+
+	// Each settlement is a trade of its own, as a later one of the same series must replay in time order.
+	order := ev.String() + " " + i.String() + " " + at.Format(time.RFC3339)
+	stock := Instrument{Kind: StockKind, Ticker: i.Ticker, Multiplier: 1}
+	return l.Add(
+		Fill{
+			Time:     at,
+			Asset:    i.Asset(side, contracts, premium),
+			Side:     side,
+			Quantity: contracts,
+			Price:    premium,
+			OrderID:  order,
+			Event:    ev},
+		Fill{
+			Time:     at,
+			Asset:    stock.Asset(shares, int(math.Round(n)), price),
+			Side:     shares,
+			Quantity: int(math.Round(n)),
+			Price:    price,
+			OrderID:  order,
+			Event:    ev})
+}
+
+// Records a roll: the closing fills and the opening fills are recorded as Rolled under one order, so the legs
+// opened continue the strategy the closed legs belonged to and its P&L and credits accumulate across the roll.
+// The order id of the first closing fill is used, or one is made up when it has none.
+func (l *Ledger) Roll(closing, opening []Fill) error {
+	if len(closing) == 0 || len(opening) == 0 {
+		return ErrNotClosing
+	}
+	held := make(map[Instrument]int)
+	for _, p := range l.Positions(closing[0].Time) {
+		held[p.Instrument] = p.Quantity
+	}
+	for _, f := range closing {
+		i, e := InstrumentOf(f.Asset)
+		if e != nil {
+			return e
+		}
+		if d := f.delta(); held[i] == 0 || (held[i] > 0) == (d > 0) || abs(d) > abs(held[i]) {
+			return ErrNotClosing
+		}
+		held[i] += f.delta()
+	}
+
+	order := closing[0].OrderID
+	if order == "" {
+		i, _ := InstrumentOf(closing[0].Asset)
+		order = Rolled.String() + " " + i.String() + " " + closing[0].Time.Format(time.RFC3339)
+	}
+	var fs []Fill
+	for _, f := range append(append([]Fill(nil), closing...), opening...) {
+		f.OrderID, f.Event = order, Rolled
+		fs = append(fs, f)
+	}
+	return l.Add(fs...)
+}
+
+// Least amount in the money at which expiring contracts are exercised and assigned, following the OCC's
+// exercise by exception threshold.
+const AutoExerciseThreshold = 0.01
+
+var ErrNoSettlement = errors.New("no settlement price for an expiring option's underlying")
+
+// Settlement prices of underlyings by ticker.
+type Settlements map[string]float64
+
+// Settles every open contract expiring on the day of expiration against the settlement price of its
+// underlying: contracts less than AutoExerciseThreshold in the money expire worthless, long contracts in the
+// money are exercised and short ones assigned. Returns the strategies that remain open, grouped as they were
+// held, with the shares delivered joining the strategy whose contracts delivered them. See Strategies.
+func (l *Ledger) Expire(expiration time.Time, s Settlements) ([]data.Strategy, error) {
+	for _, p := range l.Positions(expiration) {
+		i := p.Instrument
+		if i.Kind == StockKind || !sameDay(i.Expiration, expiration) {
+			continue
+		}
+		price, ok := s[i.Ticker]
+		if !ok {
+			return nil, ErrNoSettlement
+		}
+
+		itm := price - i.Strike
+		if i.Kind == PutKind {
+			itm = -itm
+		}
+		// Allow for floating point error so that settling a cent in the money at, say, 190.01 counts as a cent.
+		var e error
+		switch {
+		case itm < AutoExerciseThreshold-1e-9:
+			e = l.expire(p, expiration)
+		case p.Quantity > 0:
+			e = l.Exercise(p.Asset(), p.Quantity, expiration)
+		default:
+			e = l.Assign(p.Asset(), -p.Quantity, expiration)
+		}
+		if e != nil {
+			return nil, e
+		}
+	}
+	return l.Strategies(expiration)
+}
+
+// Closes a position at no value.
+func (l *Ledger) expire(p Position, at time.Time) error {
+	side := data.Sell
+	if p.Quantity < 0 {
+		side = data.Buy
+	}
+	return l.Add(Fill{
+		Time:     at,
+		Asset:    p.Asset(),
+		Side:     side,
+		Quantity: abs(p.Quantity),
+		OrderID:  Expired.String() + " " + p.Instrument.String(),
+		Event:    Expired})
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,288 @@
+package ledger
+
+import (
+	"fmt"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// Opens the strategy in a new ledger and settles its only option leg a day later.
+func settled(fs []Fill, settle func(*Ledger, data.Asset, int, Fill) error) (Ledger, Fill, error) {
+	l := Ledger{}
+	if e := l.Add(fs...); e != nil {
+		return l, Fill{}, e
+	}
+	var o Fill
+	for _, f := range fs {
+		if i, _ := InstrumentOf(f.Asset); i.Kind != StockKind {
+			o = f
+		}
+	}
+	return l, o, settle(&l, o.Asset, o.Quantity, o)
+}
+
+func TestLifecycle(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6*math.Max(1, math.Abs(a)) }
+	assign := func(l *Ledger, a data.Asset, n int, f Fill) error { return l.Assign(a, n, f.Time.AddDate(0, 0, 1)) }
+	exercise := func(l *Ledger, a data.Asset, n int, f Fill) error { return l.Exercise(a, n, f.Time.AddDate(0, 0, 1)) }
+
+	// Shares delivered, their price and the P&L of the strategy after settling its option leg.
+	after := func(l Ledger, o Fill) (int, float64, StrategyPnL, bool) {
+		at := o.Time.AddDate(0, 0, 1)
+		i, _ := InstrumentOf(o.Asset)
+		stock := Instrument{Kind: StockKind, Ticker: i.Ticker, Multiplier: 1}
+		r, e := l.PnL(at, Quotes{stock: i.Strike})
+		if e != nil || len(r.Strategies) != 1 {
+			return 0, 0, StrategyPnL{}, false
+		}
+		shares, price := 0, 0.0
+		for _, p := range l.Positions(at) {
+			if p.Instrument == i {
+				return 0, 0, StrategyPnL{}, false
+			}
+			if p.Instrument == stock {
+				shares, price = p.Quantity, p.Price
+			}
+		}
+		return shares, price, r.Strategies[0], true
+	}
+
+	ps.Property("Assigned short put becomes long stock", prop.ForAll(
+		func(fs []Fill) bool {
+			l, o, e := settled(fs, assign)
+			shares, price, s, ok := after(l, o)
+			k := o.Asset.(data.Put).Strike
+			return e == nil && ok && shares == 100*o.Quantity && s.Type == data.NakedStock && s.Dir == data.L &&
+				near(price, k-o.Price) && near(s.Realized, 0) && near(s.Unrealized, o.Price*100*float64(o.Quantity))
+		},
+		GenOpeningFills(data.GenShortNakedPutStrategy(gen.Const("AAA")))))
+
+	ps.Property("Assigned covered call is flat", prop.ForAll(
+		func(fs []Fill) bool {
+			l, o, e := settled(fs, assign)
+			shares, _, s, ok := after(l, o)
+			c := o.Asset.(data.Call)
+			gain := (o.Price + c.Strike - fs[0].Price) * 100
+			return e == nil && ok && shares == 0 && !s.Open() && near(s.Realized, gain)
+		},
+		GenOpeningFills(data.GenShortCoveredCallStrategy(gen.Const("AAA")))))
+
+	ps.Property("Exercised long call becomes long stock", prop.ForAll(
+		func(fs []Fill) bool {
+			l, o, e := settled(fs, exercise)
+			shares, price, s, ok := after(l, o)
+			k := o.Asset.(data.Call).Strike
+			return e == nil && ok && shares == 100*o.Quantity && s.Type == data.NakedStock && s.Dir == data.L &&
+				near(price, k+o.Price) && near(s.Realized, 0) && near(s.Unrealized, -o.Price*100*float64(o.Quantity))
+		},
+		GenOpeningFills(data.GenLongNakedCallStrategy(gen.Const("AAA")))))
+
+	ps.Property("Exercised long put becomes short stock", prop.ForAll(
+		func(fs []Fill) bool {
+			l, o, e := settled(fs, exercise)
+			shares, price, s, ok := after(l, o)
+			k := o.Asset.(data.Put).Strike
+			return e == nil && ok && shares == -100*o.Quantity && s.Type == data.NakedStock && s.Dir == data.S &&
+				near(price, math.Max(0, k-o.Price)) && near(s.Realized, 0)
+		},
+		GenOpeningFills(data.GenLongNakedPutStrategy(gen.Const("AAA")))))
+
+	ps.Property("Assigning a series again is a trade of its own", prop.ForAll(
+		func(fs []Fill) bool {
+			l, o, e := settled(fs, assign)
+			day := func(n int) time.Time { return o.Time.AddDate(0, 0, n) }
+			ps := l.Positions(day(1))
+			if e != nil || len(ps) != 1 {
+				return false
+			}
+			shares := ps[0]
+			again := o
+			again.Time, again.OrderID, again.Price = day(3), "again", o.Price+1
+			if l.Add(Fill{Time: day(2), Asset: shares.Asset(), Side: data.Sell, Quantity: shares.Quantity,
+				Price: o.Asset.(data.Put).Strike, OrderID: "sold"}, again) != nil ||
+				l.Assign(again.Asset, again.Quantity, day(4)) != nil {
+				return false
+			}
+			ss, e := l.Strategies(day(4))
+			r, err := l.PnL(day(4), Quotes{shares.Instrument: shares.Price})
+			realized := 0.0
+			for _, g := range r.Strategies {
+				realized += g.Realized
+			}
+			k := o.Asset.(data.Put).Strike
+			return e == nil && err == nil && len(ss) == 1 && ss[0].Type == data.NakedStock &&
+				near(ss[0].Stocks[0].Price, math.Max(0, k-again.Price)) &&
+				near(realized, (k-shares.Price)*float64(shares.Quantity))
+		},
+		GenOpeningFills(data.GenShortNakedPutStrategy(gen.Const("AAA")))))
+
+	ps.Property("Only contracts held on the right side settle", prop.ForAll(
+		func(fs []Fill, st data.Stock) bool {
+			_, _, wrongSide := settled(fs, exercise)
+			_, _, tooMany := settled(fs, func(l *Ledger, a data.Asset, n int, f Fill) error {
+				return l.Assign(a, n+1, f.Time)
+			})
+			l := Ledger{}
+			return wrongSide == ErrNoContracts && tooMany == ErrNoContracts && l.Assign(st, 1, Epoch) == ErrNotOption
+		},
+		GenOpeningFills(data.GenShortNakedPutStrategy(gen.Const("AAA"))), data.GenStock(gen.Const("AAA"))))
+
+	ps.Property("Contracts must deliver whole shares", prop.ForAll(
+		func(fs []Fill, m float64) bool {
+			if n := float64(fs[0].Quantity) * m; math.Abs(n-math.Round(n)) < 1e-6 {
+				return true
+			}
+			p := fs[0].Asset.(data.Put)
+			p.Multiplier = m
+			fs[0].Asset = p
+			l, _, e := settled(fs, assign)
+			return e == ErrDeliverable && len(l.Fills()) == 1
+		},
+		GenOpeningFills(data.GenShortNakedPutStrategy(gen.Const("AAA"))), gen.Float64Range(100.01, 100.99)))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
+
+func TestExpire(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	strategies := gen.OneGenOf(
+		data.GenShortIronCondorStrategy(gen.Const("AAA")),
+		data.GenShortStrangleStrategy(gen.Const("AAA")),
+		data.GenShortCoveredCallStrategy(gen.Const("AAA")),
+		data.GenLongPutSpreadStrategy(gen.Const("AAA")))
+
+	// Shares each expiring position delivers when settled at the price.
+	delivered := func(ps []Position, price float64) (n int) {
+		for _, p := range ps {
+			i := p.Instrument
+			switch {
+			case i.Kind == CallKind && price-i.Strike >= AutoExerciseThreshold:
+				n += p.Quantity * 100
+			case i.Kind == PutKind && i.Strike-price >= AutoExerciseThreshold:
+				n -= p.Quantity * 100
+			case i.Kind == StockKind:
+				n += p.Quantity
+			}
+		}
+		return n
+	}
+
+	ps.Property("Expiring options are settled and the rest stays in its strategy", prop.ForAll(
+		func(fs []Fill, price float64) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			want := delivered(l.Positions(data.Expiration), price)
+			ss, e := l.Expire(data.Expiration, Settlements{"AAA": price})
+			if e != nil {
+				return false
+			}
+			open := l.Positions(data.Expiration)
+			for _, q := range open {
+				if q.Instrument.Kind != StockKind {
+					return false
+				}
+			}
+			if want == 0 {
+				return len(open) == 0 && len(ss) == 0
+			}
+			return len(open) == 1 && open[0].Quantity == want && len(ss) == 1 && ss[0].Type == data.NakedStock
+		},
+		GenOpeningFills(strategies), gen.Float64Range(data.MinStrike/2, data.MaxStrike*1.5)))
+
+	ps.Property("Strategies held apart are not regrouped", prop.ForAll(
+		func(fs []Fill) bool {
+			l := Ledger{}
+			for k := range fs {
+				fs[k].OrderID = fmt.Sprintf("order-%d", k)
+			}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			ss, e := l.Expire(data.Expiration.AddDate(0, 0, -1), Settlements{})
+			p, _ := l.Portfolio(data.Expiration)
+			return e == nil && len(ss) == 2 && ss[0].Type == data.NakedStock && ss[1].Type == data.NakedCall &&
+				len(p.Strategies) == 1 && p.Strategies[0].Type == data.CoveredCall
+		},
+		GenOpeningFills(data.GenShortCoveredCallStrategy(gen.Const("AAA")))))
+
+	ps.Property("Contracts barely in the money expire worthless", prop.ForAll(
+		func(fs []Fill, short bool) bool {
+			l := Ledger{}
+			if short {
+				fs[0].Side = data.Sell
+			}
+			c := fs[0].Asset.(data.Call)
+			if l.Add(fs...) != nil {
+				return false
+			}
+			if _, e := l.Expire(data.Expiration, Settlements{"AAA": c.Strike + AutoExerciseThreshold/2}); e != nil {
+				return false
+			}
+			return len(l.Positions(data.Expiration)) == 0 && l.Fills()[1].Event == Expired
+		},
+		GenOpeningFills(data.GenLongNakedCallStrategy(gen.Const("AAA"))), gen.Bool()))
+
+	ps.Property("Contracts a cent in the money are exercised or assigned", prop.ForAll(
+		func(fs []Fill, short bool) bool {
+			l := Ledger{}
+			if short {
+				fs[0].Side = data.Sell
+			}
+			i, _ := InstrumentOf(fs[0].Asset)
+			price := i.Strike + AutoExerciseThreshold
+			if i.Kind == PutKind {
+				price = i.Strike - AutoExerciseThreshold
+			}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			if _, e := l.Expire(data.Expiration, Settlements{"AAA": price}); e != nil {
+				return false
+			}
+			ev := Exercised
+			if short {
+				ev = Assigned
+			}
+			open := l.Positions(data.Expiration)
+			return len(open) == 1 && open[0].Instrument.Kind == StockKind && l.Fills()[1].Event == ev
+		},
+		gen.OneGenOf(
+			GenOpeningFills(data.GenLongNakedCallStrategy(gen.Const("AAA"))),
+			GenOpeningFills(data.GenLongNakedPutStrategy(gen.Const("AAA"))),
+			GenOpeningFills(data.GenLongNakedCallStrategy(gen.Const("AAA")).Map(func(s data.Strategy) data.Strategy {
+				s.Lc[0].Strike = 190
+				return s
+			})),
+			GenOpeningFills(data.GenLongNakedPutStrategy(gen.Const("AAA")).Map(func(s data.Strategy) data.Strategy {
+				s.Lp[0].Strike = 452.5
+				return s
+			}))),
+		gen.Bool()))
+
+	ps.Property("Expiring options need a settlement price", prop.ForAll(
+		func(fs []Fill) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			_, missing := l.Expire(data.Expiration, Settlements{"BBB": 1})
+			_, other := l.Expire(data.Expiration.AddDate(0, 0, 1), Settlements{})
+			return missing == ErrNoSettlement && other == nil && len(l.Positions(data.Expiration)) == 4
+		},
+		GenOpeningFills(data.GenShortIronCondorStrategy(gen.Const("AAA")))))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,338 @@
+package ledger
+
+import (
+	"errors"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"sort"
+	"time"
+)
+
+var ErrNoQuote = errors.New("no quote for an open position")
+
+// Current prices per share of instruments, used to mark open positions.
+type Quotes map[Instrument]float64
+
+// Profit and loss in currency. Fees are kept apart from trading gains and are not included in Realized.
+type PnL struct {
+	Realized   float64
+	Unrealized float64
+	Fees       float64
+}
+
+// Net profit after fees.
+func (p PnL) Total() float64 {
+	return p.Realized + p.Unrealized - p.Fees
+}
+
+func (p PnL) add(o PnL) PnL {
+	return PnL{p.Realized + o.Realized, p.Unrealized + o.Unrealized, p.Fees + o.Fees}
+}
+
+// Profit and loss of one instrument within a strategy.
+type LegPnL struct {
+	Instrument Instrument
+	Quantity   int     // Open quantity. Signed: negative when short.
+	Price      float64 // Average opening price per share of the open quantity.
+	Mark       float64 // Quote the open quantity is marked at. Zero when the leg is flat.
+	PnL
+}
+
+// Profit and loss of the legs opened together as one strategy.
+// Legs keep their grouping across partial closes, and legs opened by an order that closes part of the
+// strategy, as when rolling, join it. Type and Dir are those recognized after the legs were last opened.
+type StrategyPnL struct {
+	Ticker  string
+	Type    data.Type
+	Dir     data.Direction
+	Opened  time.Time
+	Legs    []LegPnL
+	History []Step // Every order that traded the strategy, from first open to final close.
+	PnL
+}
+
+// The part of one order that traded a strategy.
+type Step struct {
+	OrderID string
+	Time    time.Time
+	Closed  []Fill         // Quantities of the order's fills that closed legs of the strategy.
+	Opened  []Fill         // Quantities of the order's fills that opened legs of the strategy.
+	Type    data.Type      // Type of the strategy after the step.
+	Dir     data.Direction // Direction of the strategy after the step.
+}
+
+// Net cash received for the step after fees. Negative for debits.
+func (s Step) Credit() (c float64) {
+	for _, f := range append(s.Closed, s.Opened...) {
+		i, _ := InstrumentOf(f.Asset)
+		v := f.Price * float64(f.Quantity) * i.Multiplier
+		if f.Side == data.Buy {
+			v = -v
+		}
+		c += v - f.Fees
+	}
+	return c
+}
+
+// True when the step both closed and opened legs, or was recorded with Roll.
+func (s Step) Rolled() bool {
+	for _, f := range append(s.Closed, s.Opened...) {
+		if f.Event == Rolled {
+			return true
+		}
+	}
+	return len(s.Closed) > 0 && len(s.Opened) > 0
+}
+
+// Net cash received over the strategy's history.
+func (s StrategyPnL) Credit() (c float64) {
+	for _, st := range s.History {
+		c += st.Credit()
+	}
+	return c
+}
+
+// Number of times the strategy was rolled.
+func (s StrategyPnL) Rolls() (n int) {
+	for _, st := range s.History {
+		if st.Rolled() {
+			n++
+		}
+	}
+	return n
+}
+
+// True while any leg of the strategy is held.
+func (s StrategyPnL) Open() bool {
+	for _, l := range s.Legs {
+		if l.Quantity != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Profit and loss of every strategy opened up to a point in time, in the order they were opened.
+type Report struct {
+	Strategies []StrategyPnL
+}
+
+// Sum over every strategy.
+func (r Report) Total() (p PnL) {
+	for _, s := range r.Strategies {
+		p = p.add(s.PnL)
+	}
+	return p
+}
+
+// Returns the sorted tickers traded.
+func (r Report) Tickers() []string {
+	seen := make(map[string]bool)
+	var ts []string
+	for _, s := range r.Strategies {
+		if !seen[s.Ticker] {
+			seen[s.Ticker] = true
+			ts = append(ts, s.Ticker)
+		}
+	}
+	sort.Strings(ts)
+	return ts
+}
+
+// Sum over the strategies on a ticker.
+func (r Report) Ticker(ticker string) (p PnL) {
+	for _, s := range r.Strategies {
+		if s.Ticker == ticker {
+			p = p.add(s.PnL)
+		}
+	}
+	return p
+}
+
+// Replays every trade up to and including time at, grouping the legs each order opens into a strategy,
+// and reports realized P&L on closed quantities and unrealized P&L on open quantities marked at the quotes.
+// Closing fills are matched against the oldest strategy holding the opposite side of the instrument, and
+// fills opened under the same order, as recorded by Roll, join that strategy.
+// Returns ErrNoQuote when an open leg has no quote.
+func (l *Ledger) PnL(at time.Time, q Quotes) (Report, error) {
+	gs, e := l.replay(at)
+	if e != nil {
+		return Report{}, e
+	}
+
+	r := Report{}
+	for _, g := range gs {
+		if e := g.mark(q); e != nil {
+			return Report{}, e
+		}
+		r.Strategies = append(r.Strategies, *g)
+	}
+	return r, nil
+}
+
+// Groups the trades up to time at into strategies without marking them. See PnL.
+func (l *Ledger) replay(at time.Time) ([]*StrategyPnL, error) {
+	var gs []*StrategyPnL
+	for _, t := range l.Trades() {
+		var opening []Fill
+		joined := make(map[string]*StrategyPnL)
+		steps := make(map[*StrategyPnL]*Step)
+		var order []*StrategyPnL
+		step := func(g *StrategyPnL, f Fill) *Step {
+			if steps[g] == nil {
+				steps[g] = &Step{OrderID: t.OrderID, Time: f.Time}
+				order = append(order, g)
+			}
+			return steps[g]
+		}
+		for _, f := range t.Fills {
+			if f.Time.After(at) {
+				continue
+			}
+			i, _ := InstrumentOf(f.Asset)
+			fee := f.Fees / float64(f.Quantity)
+			left := f.Quantity
+			for _, g := range gs {
+				if left == 0 {
+					break
+				}
+				if n := g.close(i, f, left, fee); n > 0 {
+					c := f
+					c.Quantity, c.Fees = n, fee*float64(n)
+					st := step(g, f)
+					st.Closed = append(st.Closed, c)
+					left -= n
+					if joined[g.Ticker] == nil && t.OrderID != "" {
+						joined[g.Ticker] = g
+					}
+				}
+			}
+			if left > 0 {
+				f.Fees, f.Quantity = fee*float64(left), left
+				opening = append(opening, f)
+			}
+		}
+
+		for _, f := range opening {
+			i, _ := InstrumentOf(f.Asset)
+			g := joined[i.Ticker]
+			if g == nil {
+				g = &StrategyPnL{Ticker: i.Ticker, Opened: f.Time}
+				gs = append(gs, g)
+				joined[i.Ticker] = g
+			}
+			// An order trading both sides of an instrument closes what it opened itself.
+			st := step(g, f)
+			fee := f.Fees / float64(f.Quantity)
+			if n := g.close(i, f, f.Quantity, fee); n > 0 {
+				c := f
+				c.Quantity, c.Fees = n, fee*float64(n)
+				st.Closed = append(st.Closed, c)
+				f.Quantity, f.Fees = f.Quantity-n, fee*float64(f.Quantity-n)
+			}
+			if f.Quantity > 0 {
+				st.Opened = append(st.Opened, f)
+				g.open(i, f)
+			}
+		}
+		for _, g := range order {
+			if len(steps[g].Opened) > 0 {
+				if e := g.classify(); e != nil {
+					return nil, e
+				}
+			}
+			steps[g].Type, steps[g].Dir = g.Type, g.Dir
+			g.History = append(g.History, *steps[g])
+		}
+	}
+	return gs, nil
+}
+
+// Closes up to n units of the instrument held on the opposite side of the fill and returns how many were closed.
+func (s *StrategyPnL) close(i Instrument, f Fill, n int, fee float64) int {
+	for k := range s.Legs {
+		l := &s.Legs[k]
+		if l.Instrument != i || l.Quantity == 0 || (l.Quantity > 0) == (f.delta() > 0) {
+			continue
+		}
+		c := min(n, abs(l.Quantity))
+		gain := (f.Price - l.Price) * float64(c) * i.Multiplier
+		if l.Quantity < 0 {
+			gain, c = -gain, -c
+		}
+		l.Quantity -= c
+		l.Realized += gain
+		l.Fees += fee * float64(abs(c))
+		if l.Quantity == 0 {
+			l.Price = 0
+		}
+		return abs(c)
+	}
+	return 0
+}
+
+// Adds the fill to the strategy, averaging its price into the leg it trades.
+func (s *StrategyPnL) open(i Instrument, f Fill) {
+	for k := range s.Legs {
+		l := &s.Legs[k]
+		if l.Instrument != i {
+			continue
+		}
+		q := abs(l.Quantity) + f.Quantity
+		l.Price = (l.Price*float64(abs(l.Quantity)) + f.Price*float64(f.Quantity)) / float64(q)
+		l.Quantity += f.delta()
+		l.Fees += f.Fees
+		return
+	}
+	s.Legs = append(s.Legs, LegPnL{Instrument: i, Quantity: f.delta(), Price: f.Price, PnL: PnL{Fees: f.Fees}})
+}
+
+// Recognizes the strategy formed by the open legs.
+func (s *StrategyPnL) classify() error {
+	st, e := s.strategy()
+	if e != nil {
+		return e
+	}
+	s.Type, s.Dir = st.Type, st.Dir
+	return nil
+}
+
+// Builds a strategy from the open legs.
+func (s *StrategyPnL) strategy() (data.Strategy, error) {
+	var ss data.Stocks
+	var ps data.Puts
+	var cs data.Calls
+	for _, l := range s.Legs {
+		if l.Quantity == 0 {
+			continue
+		}
+		p := Position{Instrument: l.Instrument, Quantity: l.Quantity, Price: l.Price}
+		switch a := p.Asset().(type) {
+		case data.Stock:
+			ss = append(ss, a)
+		case data.Put:
+			ps = append(ps, a)
+		case data.Call:
+			cs = append(cs, a)
+		}
+	}
+	return data.NewStrategy(ss, ps, cs)
+}
+
+// Marks open legs at the quotes and totals the legs.
+func (s *StrategyPnL) mark(q Quotes) error {
+	s.PnL = PnL{}
+	for k := range s.Legs {
+		l := &s.Legs[k]
+		l.Mark, l.Unrealized = 0, 0
+		if l.Quantity != 0 {
+			m, ok := q[l.Instrument]
+			if !ok {
+				return ErrNoQuote
+			}
+			l.Mark = m
+			l.Unrealized = (m - l.Price) * float64(l.Quantity) * l.Instrument.Multiplier
+		}
+		s.PnL = s.PnL.add(l.PnL)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package ledger
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// Quotes every instrument the fills trade at its fill price moved by move.
+func quotesOf(fs []Fill, move float64) Quotes {
+	q := make(Quotes)
+	for _, f := range fs {
+		i, _ := InstrumentOf(f.Asset)
+		q[i] = f.Price + move
+	}
+	return q
+}
+
+// Fills reversing each fill at its price moved by move, a day later and under order.
+func closingFills(fs []Fill, move float64, order string) []Fill {
+	var cs []Fill
+	for _, f := range fs {
+		c := f
+		c.Time, c.Price, c.OrderID, c.Fees = f.Time.AddDate(0, 0, 1), f.Price+move, order, 0
+		c.Side = data.Buy
+		if f.Side == data.Buy {
+			c.Side = data.Sell
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+// Value of moving every fill's price by move.
+func moved(fs []Fill, move float64) (v float64) {
+	for _, f := range fs {
+		i, _ := InstrumentOf(f.Asset)
+		v += move * float64(f.delta()) * i.Multiplier
+	}
+	return v
+}
+
+func TestPnL(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	condor := GenOpeningFills(data.GenShortIronCondorStrategy(gen.Const("AAA")))
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+	ps.Property("Open strategy marked at quotes is unrealized", prop.ForAll(
+		func(fs []Fill, move float64) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			r, e := l.PnL(fs[0].Time, quotesOf(fs, move))
+			if e != nil || len(r.Strategies) != 1 {
+				return false
+			}
+			s := r.Strategies[0]
+			return s.Type == data.IronCondor && s.Open() && len(s.Legs) == 4 &&
+				s.Realized == 0 && near(s.Unrealized, moved(fs, move))
+		},
+		condor, gen.Float64Range(0, 1)))
+
+	ps.Property("Closed strategy is realized", prop.ForAll(
+		func(fs []Fill, move float64) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil || l.Add(closingFills(fs, move, "close")...) != nil {
+				return false
+			}
+			r, e := l.PnL(fs[0].Time.AddDate(0, 0, 1), nil)
+			if e != nil || len(r.Strategies) != 1 {
+				return false
+			}
+			s := r.Strategies[0]
+			return s.Type == data.IronCondor && !s.Open() && s.Unrealized == 0 && near(s.Realized, moved(fs, move))
+		},
+		condor, gen.Float64Range(0, 1)))
+
+	ps.Property("Partial close keeps the grouping", prop.ForAll(
+		func(fs []Fill, move float64) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil || l.Add(closingFills(fs[:1], move, "close")...) != nil {
+				return false
+			}
+			r, e := l.PnL(fs[0].Time.AddDate(0, 0, 1), quotesOf(fs, 0))
+			if e != nil || len(r.Strategies) != 1 {
+				return false
+			}
+			s := r.Strategies[0]
+			return s.Type == data.IronCondor && s.Open() && s.Legs[0].Quantity == 0 &&
+				near(s.Realized, moved(fs[:1], move))
+		},
+		condor, gen.Float64Range(0, 1)))
+
+	ps.Property("Rolled strategy reports as one position", prop.ForAll(
+		func(fs []Fill, move float64) bool {
+			l := Ledger{}
+			roll := closingFills(fs, move, "roll")
+			for _, f := range fs {
+				o := f
+				o.Time = roll[0].Time
+				o.OrderID = "roll"
+				switch a := f.Asset.(type) {
+				case data.Put:
+					a.Expiration = a.Expiration.AddDate(0, 1, 0)
+					o.Asset = a
+				case data.Call:
+					a.Expiration = a.Expiration.AddDate(0, 1, 0)
+					o.Asset = a
+				}
+				roll = append(roll, o)
+			}
+			if l.Add(fs...) != nil || l.Add(roll...) != nil {
+				return false
+			}
+			r, e := l.PnL(roll[0].Time, quotesOf(roll[4:], 0))
+			if e != nil || len(r.Strategies) != 1 {
+				return false
+			}
+			s := r.Strategies[0]
+			return s.Type == data.IronCondor && s.Open() && len(s.Legs) == 8 && s.Opened.Equal(fs[0].Time) &&
+				near(s.Realized, moved(fs, move)) && near(s.Unrealized, 0)
+		},
+		condor, gen.Float64Range(0, 1)))
+
+	ps.Property("Ticker P&L sums to the total", prop.ForAll(
+		func(a, b []Fill) bool {
+			l := Ledger{}
+			if l.Add(a...) != nil || l.Add(b...) != nil {
+				return false
+			}
+			at := Epoch.AddDate(1, 0, 0)
+			q := quotesOf(append(a, b...), 0.5)
+			r, e := l.PnL(at, q)
+			if e != nil {
+				return false
+			}
+			var sum PnL
+			for _, t := range r.Tickers() {
+				sum = sum.add(r.Ticker(t))
+			}
+			total := r.Total()
+			return len(r.Tickers()) == 2 && near(sum.Total(), total.Total()) &&
+				near(total.Fees, fees(a)+fees(b)) && near(total.Realized+total.Unrealized, marked(append(a, b...), q))
+		},
+		gen.SliceOfN(10, GenFill(GenAsset(gen.Const("AAA")))),
+		gen.SliceOfN(10, GenFill(GenAsset(gen.Const("BBB"))))))
+
+	ps.Property("An order trading both sides of an instrument closes what it opened", prop.ForAll(
+		func(f Fill, move float64) bool {
+			l := Ledger{}
+			back := closingFills([]Fill{f}, move, f.OrderID)[0]
+			if f.OrderID == "" || l.Add(f, back) != nil {
+				return true
+			}
+			i, _ := InstrumentOf(f.Asset)
+			r, e := l.PnL(back.Time, Quotes{})
+			return e == nil && len(r.Strategies) == 1 && !r.Strategies[0].Open() &&
+				near(r.Total().Realized, move*float64(f.delta())*i.Multiplier)
+		},
+		GenFill(GenAsset(gen.Const("AAA"))), gen.Float64Range(-1, 1)))
+
+	ps.Property("Open legs need quotes", prop.ForAll(
+		func(fs []Fill) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			_, e := l.PnL(fs[0].Time, Quotes{})
+			_, before := l.PnL(fs[0].Time.Add(-time.Minute), Quotes{})
+			return e == ErrNoQuote && before == nil
+		},
+		condor))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
+
+// Value of every fill marked at the quotes.
+func marked(fs []Fill, q Quotes) (v float64) {
+	for _, f := range fs {
+		i, _ := InstrumentOf(f.Asset)
+		v += (q[i] - f.Price) * float64(f.delta()) * i.Multiplier
+	}
+	return v
+}
+
+func fees(fs []Fill) (v float64) {
+	for _, f := range fs {
+		v += f.Fees
+	}
+	return v
+}
@@ -0,0 +1,96 @@
+package ledger
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"os"
+	"testing"
+)
+
+// Fills moving each option fill a month further out, opened at the same time as closing.
+func rolledOut(fs []Fill, order string) []Fill {
+	var out []Fill
+	for _, f := range fs {
+		o := f
+		o.OrderID = order
+		switch a := f.Asset.(type) {
+		case data.Put:
+			a.Expiration = a.Expiration.AddDate(0, 1, 0)
+			o.Asset = a
+		case data.Call:
+			a.Expiration = a.Expiration.AddDate(0, 1, 0)
+			o.Asset = a
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+func TestRoll(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6*math.Max(1, math.Abs(a)) }
+
+	ps.Property("Rolls chain a strategy from first open to final close", prop.ForAll(
+		func(fs []Fill, move float64) bool {
+			l := Ledger{}
+			if l.Add(fs...) != nil {
+				return false
+			}
+			all := append([]Fill(nil), fs...)
+			legs := fs
+			for _, id := range []string{"first", "second"} {
+				closing := closingFills(legs, move, id)
+				opening := rolledOut(legs, "open")
+				for j := range opening {
+					opening[j].Time = closing[j].Time
+				}
+				if l.Roll(closing, opening) != nil {
+					return false
+				}
+				all, legs = append(append(all, closing...), opening...), opening
+			}
+			final := closingFills(legs, move, "final")
+			if l.Add(final...) != nil {
+				return false
+			}
+			all = append(all, final...)
+
+			r, e := l.PnL(final[0].Time, nil)
+			if e != nil || len(r.Strategies) != 1 {
+				return false
+			}
+			s := r.Strategies[0]
+			h := s.History
+			credit := 0.0
+			for _, f := range all {
+				i, _ := InstrumentOf(f.Asset)
+				credit -= f.Price * float64(f.delta()) * i.Multiplier
+			}
+			return len(h) == 4 && s.Rolls() == 2 && s.Type == data.Strangle && !s.Open() &&
+				len(h[0].Closed) == 0 && len(h[0].Opened) == 2 && h[1].Rolled() && h[2].Rolled() &&
+				len(h[3].Opened) == 0 && len(h[3].Closed) == 2 && !h[3].Rolled() &&
+				h[2].Time.After(h[1].Time) && near(s.Credit(), credit) && near(s.Realized, credit)
+		},
+		GenOpeningFills(data.GenShortStrangleStrategy(gen.Const("AAA"))), gen.Float64Range(0, 1)))
+
+	ps.Property("Rolls must close held legs", prop.ForAll(
+		func(fs []Fill) bool {
+			l := Ledger{}
+			unheld := l.Roll(closingFills(fs, 0, ""), rolledOut(fs, ""))
+			if l.Add(fs...) != nil {
+				return false
+			}
+			opening := l.Roll(fs, rolledOut(fs, ""))
+			empty := l.Roll(closingFills(fs, 0, ""), nil)
+			return unheld == ErrNotClosing && opening == ErrNotClosing && empty == ErrNotClosing &&
+				l.Roll(closingFills(fs, 0, ""), rolledOut(fs, "")) == nil && l.Fills()[2].Event == Rolled
+		},
+		GenOpeningFills(data.GenShortStrangleStrategy(gen.Const("AAA")))))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
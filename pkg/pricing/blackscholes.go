@@ -0,0 +1,159 @@
+package pricing
+
+import (
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"time"
+)
+
+// Days per year used to convert between calendar days and years.
+const DaysPerYear float64 = 365
+
+// Market inputs needed to price a single option.
+type Inputs struct {
+	Spot     float64 // Price of the underlying.
+	Vol      float64 // Annualised volatility, e.g. 0.25 for 25%.
+	Rate     float64 // Continuously compounded risk-free rate.
+	Dividend float64 // Continuous dividend yield.
+	T        float64 // Years until expiration.
+}
+
+// Theoretical value and sensitivities of an option or a position.
+// Theta is per calendar day, Vega per 1% change in volatility and Rho per 1% change in rate.
+type Greeks struct {
+	Value float64
+	Delta float64
+	Gamma float64
+	Theta float64
+	Vega  float64
+	Rho   float64
+}
+
+func (g Greeks) add(o Greeks, n float64) Greeks {
+	return Greeks{
+		Value: g.Value + n*o.Value,
+		Delta: g.Delta + n*o.Delta,
+		Gamma: g.Gamma + n*o.Gamma,
+		Theta: g.Theta + n*o.Theta,
+		Vega:  g.Vega + n*o.Vega,
+		Rho:   g.Rho + n*o.Rho,
+	}
+}
+
+// Prices one share's worth of a put with the Black-Scholes-Merton model.
+func Put(p data.Put, in Inputs) Greeks {
+	return blackScholes(false, p.Strike, in)
+}
+
+// Prices one share's worth of a call with the Black-Scholes-Merton model.
+func Call(c data.Call, in Inputs) Greeks {
+	return blackScholes(true, c.Strike, in)
+}
+
+func blackScholes(call bool, k float64, in Inputs) Greeks {
+	s, v, r, q, t := in.Spot, in.Vol, in.Rate, in.Dividend, in.T
+
+	// At or past expiration only intrinsic value remains.
+	if t <= 0 {
+		if call && s > k {
+			return Greeks{Value: s - k, Delta: 1}
+		} else if !call && s < k {
+			return Greeks{Value: k - s, Delta: -1}
+		}
+		return Greeks{}
+	}
+
+	dq, dr := math.Exp(-q*t), math.Exp(-r*t)
+
+	// Without volatility the underlying grows deterministically to its forward.
+	if v <= 0 {
+		if call && s*dq > k*dr {
+			return Greeks{Value: s*dq - k*dr, Delta: dq, Rho: k * t * dr / 100}
+		} else if !call && s*dq < k*dr {
+			return Greeks{Value: k*dr - s*dq, Delta: -dq, Rho: -k * t * dr / 100}
+		}
+		return Greeks{}
+	}
+
+	sq := v * math.Sqrt(t)
+	d1 := (math.Log(s/k) + (r-q+v*v/2)*t) / sq
+	d2 := d1 - sq
+
+	g := Greeks{
+		Gamma: dq * pdf(d1) / (s * sq),
+		Vega:  s * dq * pdf(d1) * math.Sqrt(t) / 100,
+	}
+	decay := -s * dq * pdf(d1) * v / (2 * math.Sqrt(t))
+
+	if call {
+		g.Value = s*dq*cdf(d1) - k*dr*cdf(d2)
+		g.Delta = dq * cdf(d1)
+		g.Theta = (decay - r*k*dr*cdf(d2) + q*s*dq*cdf(d1)) / DaysPerYear
+		g.Rho = k * t * dr * cdf(d2) / 100
+	} else {
+		g.Value = k*dr*cdf(-d2) - s*dq*cdf(-d1)
+		g.Delta = -dq * cdf(-d1)
+		g.Theta = (decay + r*k*dr*cdf(-d2) - q*s*dq*cdf(-d1)) / DaysPerYear
+		g.Rho = -k * t * dr * cdf(-d2) / 100
+	}
+	return g
+}
+
+// Standard normal cumulative distribution function.
+func cdf(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// Standard normal probability density function.
+func pdf(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+// Market state shared by every leg of a strategy. Time to expiry is taken per leg from Now.
+type Market struct {
+	Spot     float64
+	Vol      float64
+	Rate     float64
+	Dividend float64
+	Now      time.Time
+}
+
+// Inputs for an option expiring at the given time.
+func (m Market) Inputs(expiration time.Time) Inputs {
+	return Inputs{
+		Spot:     m.Spot,
+		Vol:      m.Vol,
+		Rate:     m.Rate,
+		Dividend: m.Dividend,
+		T:        Years(m.Now, expiration)}
+}
+
+// Fraction of a year between two times.
+func Years(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24 / DaysPerYear
+}
+
+// Aggregate Greeks of a strategy. Each option leg is scaled by its quantity and contract multiplier and
+// signed by the side of the strategy it sits on. Stocks contribute a delta of 1 per share.
+func StrategyGreeks(s *data.Strategy, m Market) (g Greeks) {
+	for _, st := range s.Stocks {
+		n := float64(st.Qty())
+		if st.Dir() == data.S {
+			n = -n
+		}
+		g = g.add(Greeks{Value: m.Spot, Delta: 1}, n)
+	}
+	for _, p := range s.Lp {
+		g = g.add(Put(p, m.Inputs(p.Expiration)), float64(p.Qty())*p.Mult())
+	}
+	for _, p := range s.Sp {
+		g = g.add(Put(p, m.Inputs(p.Expiration)), -float64(p.Qty())*p.Mult())
+	}
+	for _, c := range s.Lc {
+		g = g.add(Call(c, m.Inputs(c.Expiration)), float64(c.Qty())*c.Mult())
+	}
+	for _, c := range s.Sc {
+		g = g.add(Call(c, m.Inputs(c.Expiration)), -float64(c.Qty())*c.Mult())
+	}
+	return g
+}
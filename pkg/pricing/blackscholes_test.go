@@ -0,0 +1,135 @@
+package pricing
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"os"
+	"testing"
+)
+
+const tolerance = 1e-8
+
+// Published Black-Scholes values, the second from Hull's Options, Futures and Other Derivatives.
+func TestBlackScholesReference(t *testing.T) {
+	for _, r := range []struct {
+		strike    float64
+		in        Inputs
+		call, put float64
+	}{
+		{100, Inputs{Spot: 100, Vol: 0.2, Rate: 0.05, T: 1}, 10.450583572185565, 5.573526022256971},
+		{40, Inputs{Spot: 42, Vol: 0.2, Rate: 0.1, T: 0.5}, 4.759422392871535, 0.8085993729000958},
+		{95, Inputs{Spot: 100, Vol: 0.25, Rate: 0.03, Dividend: 0.02, T: 0.5}, 9.831948725700414, 4.412599613074562},
+	} {
+		c := Call(data.Call{Strike: r.strike}, r.in).Value
+		p := Put(data.Put{Strike: r.strike}, r.in).Value
+		if math.Abs(c-r.call) > tolerance || math.Abs(p-r.put) > tolerance {
+			t.Errorf("strike %v %+v: call %v put %v, want %v and %v", r.strike, r.in, c, p, r.call, r.put)
+		}
+	}
+}
+
+func TestBlackScholes(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	spot := gen.Float64Range(data.MinStrike, data.MaxStrike)
+
+	ps.Property("Put-call parity holds", prop.ForAll(
+		func(p data.Put, in Inputs) bool {
+			c := data.Call{Underlying: p.Underlying, Strike: p.Strike}
+			forward := in.Spot*math.Exp(-in.Dividend*in.T) - p.Strike*math.Exp(-in.Rate*in.T)
+			return math.Abs(Call(c, in).Value-Put(p, in).Value-forward) < tolerance*in.Spot
+		},
+		data.GenLongPut(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Delta is bounded by the dividend discount", prop.ForAll(
+		func(p data.Put, in Inputs) bool {
+			c := data.Call{Underlying: p.Underlying, Strike: p.Strike}
+			dq := math.Exp(-in.Dividend * in.T)
+			cd, pd := Call(c, in).Delta, Put(p, in).Delta
+			return cd >= 0 && cd <= dq && pd <= 0 && pd >= -dq && math.Abs(cd-pd-dq) < tolerance
+		},
+		data.GenLongPut(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Puts and calls share gamma and vega", prop.ForAll(
+		func(p data.Put, in Inputs) bool {
+			c := data.Call{Underlying: p.Underlying, Strike: p.Strike}
+			cg, pg := Call(c, in), Put(p, in)
+			return cg.Gamma >= 0 && cg.Vega >= 0 &&
+				math.Abs(cg.Gamma-pg.Gamma) < tolerance && math.Abs(cg.Vega-pg.Vega) < tolerance
+		},
+		data.GenLongPut(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Expired options are worth intrinsic value", prop.ForAll(
+		func(p data.Put, in Inputs) bool {
+			in.T = 0
+			c := data.Call{Underlying: p.Underlying, Strike: p.Strike}
+			return Put(p, in).Value == math.Max(p.Strike-in.Spot, 0) &&
+				Call(c, in).Value == math.Max(in.Spot-p.Strike, 0)
+		},
+		data.GenLongPut(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
+
+func TestStrategyGreeks(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	m := Market{Spot: 500, Vol: 0.3, Rate: 0.02, Now: data.Expiration.AddDate(0, 0, -30)}
+
+	ps.Property("Naked stock delta is one per share", prop.ForAll(
+		func(s data.Strategy) bool {
+			g := StrategyGreeks(&s, m)
+			return math.Abs(g.Delta) == float64(s.Stocks[0].Shares) && g.Gamma == 0
+		},
+		gen.OneGenOf(
+			data.GenLongNakedStockStrategy(data.GenTicker()),
+			data.GenShortNakedStockStrategy(data.GenTicker()))))
+
+	ps.Property("Strategy Greeks are the sum of its legs", prop.ForAll(
+		func(s data.Strategy) bool {
+			g := StrategyGreeks(&s, m)
+			var delta float64
+			for _, p := range s.Lp {
+				delta += 100 * Put(p, m.Inputs(p.Expiration)).Delta
+			}
+			for _, p := range s.Sp {
+				delta -= 100 * Put(p, m.Inputs(p.Expiration)).Delta
+			}
+			for _, c := range s.Lc {
+				delta += 100 * Call(c, m.Inputs(c.Expiration)).Delta
+			}
+			for _, c := range s.Sc {
+				delta -= 100 * Call(c, m.Inputs(c.Expiration)).Delta
+			}
+			return math.Abs(g.Delta-delta) < 1e-6
+		},
+		gen.OneGenOf(
+			data.GenShortIronCondorStrategy(data.GenTicker()),
+			data.GenLongCallButterflyStrategy(data.GenTicker()),
+			data.GenShortStrangleStrategy(data.GenTicker()))))
+
+	ps.Property("Lots scale Greeks linearly", prop.ForAll(
+		func(s data.Strategy) bool {
+			lot := s
+			lot.Sp = data.Puts{s.Sp[0]}
+			lot.Sp[0].Quantity = 10
+			lot.Sc = data.Calls{s.Sc[0]}
+			lot.Sc[0].Quantity = 10
+			g, g10 := StrategyGreeks(&s, m), StrategyGreeks(&lot, m)
+			return math.Abs(10*g.Delta-g10.Delta) < 1e-6 && math.Abs(10*g.Theta-g10.Theta) < 1e-6
+		},
+		data.GenShortStrangleStrategy(data.GenTicker())))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,25 @@
+package pricing
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"reflect"
+)
+
+const (
+	MinVol  float64 = 0.05
+	MaxVol  float64 = 1.5
+	MaxRate float64 = 0.1
+	MaxT    float64 = 2
+)
+
+func GenInputs(spot gopter.Gen) gopter.Gen {
+	return gen.Struct(
+		reflect.TypeOf(Inputs{}),
+		map[string]gopter.Gen{
+			"Spot":     spot,
+			"Vol":      gen.Float64Range(MinVol, MaxVol),
+			"Rate":     gen.Float64Range(0, MaxRate),
+			"Dividend": gen.Float64Range(0, MaxRate/2),
+			"T":        gen.Float64Range(1.0/DaysPerYear, MaxT)})
+}
@@ -0,0 +1,176 @@
+package pricing
+
+import (
+	"errors"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	MaxIV         float64 = 10
+	ivTolerance   float64 = 1e-10
+	ivIterations  int     = 200
+	intrinsicBand float64 = 1e-9
+)
+
+var (
+	ErrBelowIntrinsic = errors.New("option price is below its intrinsic value")
+	ErrAboveBound     = errors.New("option price is above its no-arbitrage upper bound")
+	ErrNoConvergence  = errors.New("implied volatility did not converge")
+)
+
+// Implied volatility of the recorded Price of a put. Vol in the inputs is ignored.
+// A price equal to the discounted intrinsic value implies zero volatility.
+func PutIV(p data.Put, in Inputs) (float64, error) {
+	return impliedVol(false, p.Strike, math.Abs(p.Price), in)
+}
+
+// Implied volatility of the recorded Price of a call. Vol in the inputs is ignored.
+// A price equal to the discounted intrinsic value implies zero volatility.
+func CallIV(c data.Call, in Inputs) (float64, error) {
+	return impliedVol(true, c.Strike, math.Abs(c.Price), in)
+}
+
+// Solves for volatility with Newton's method, falling back to bisection whenever a Newton step leaves
+// the bracket or vega is too small to make progress, as happens deep in or out of the money.
+func impliedVol(call bool, k, price float64, in Inputs) (float64, error) {
+	lower := blackScholes(call, k, Inputs{Spot: in.Spot, Rate: in.Rate, Dividend: in.Dividend, T: in.T}).Value
+	upper := in.Spot * math.Exp(-in.Dividend*in.T)
+	if !call {
+		upper = k * math.Exp(-in.Rate*in.T)
+	}
+
+	if price < lower-intrinsicBand {
+		return 0, ErrBelowIntrinsic
+	} else if price <= lower+intrinsicBand || in.T <= 0 {
+		return 0, nil
+	} else if price >= upper {
+		return 0, ErrAboveBound
+	}
+
+	value := func(v float64) Greeks {
+		in.Vol = v
+		return blackScholes(call, k, in)
+	}
+
+	lo, hi := 0.0, MaxIV
+	if value(hi).Value < price {
+		return 0, ErrNoConvergence
+	}
+
+	// Brenner-Subrahmanyam approximation as the starting point.
+	v := math.Sqrt(2*math.Pi/in.T) * price / in.Spot
+	if v <= lo || v >= hi {
+		v = 0.5
+	}
+
+	for i := 0; i < ivIterations; i++ {
+		g := value(v)
+		diff := g.Value - price
+		if math.Abs(diff) < ivTolerance {
+			return v, nil
+		}
+
+		if diff > 0 {
+			hi = v
+		} else {
+			lo = v
+		}
+
+		next := v - diff/(g.Vega*100)
+		if g.Vega <= 0 || math.IsNaN(next) || next <= lo || next >= hi {
+			next = (lo + hi) / 2
+		}
+		if hi-lo < ivTolerance {
+			return next, nil
+		}
+		v = next
+	}
+	return v, ErrNoConvergence
+}
+
+// Implied volatility of a single leg of a strategy.
+type LegIV struct {
+	Call       bool
+	Dir        data.Direction
+	Strike     float64
+	Expiration time.Time
+	IV         float64
+	Err        error
+}
+
+// Summary of how implied volatility varies across the legs of a strategy.
+type Skew struct {
+	PutIV  float64 // Mean implied volatility of the put legs.
+	CallIV float64 // Mean implied volatility of the call legs.
+	Skew   float64 // PutIV - CallIV. Zero unless the strategy holds both puts and calls.
+	Slope  float64 // Least squares change in implied volatility per unit of strike.
+	Min    float64
+	Max    float64
+}
+
+// Per-leg implied volatilities of a strategy and a skew summary over the legs that solved.
+type IVReport struct {
+	Legs []LegIV
+	Skew Skew
+}
+
+// Solves the implied volatility of every option leg of a strategy from its recorded Price.
+func StrategyIV(s *data.Strategy, m Market) (r IVReport) {
+	for _, ps := range []data.Puts{s.Lp, s.Sp} {
+		for _, p := range ps {
+			iv, e := PutIV(p, m.Inputs(p.Expiration))
+			r.Legs = append(r.Legs, LegIV{false, p.Dir(), p.Strike, p.Expiration, iv, e})
+		}
+	}
+	for _, cs := range []data.Calls{s.Lc, s.Sc} {
+		for _, c := range cs {
+			iv, e := CallIV(c, m.Inputs(c.Expiration))
+			r.Legs = append(r.Legs, LegIV{true, c.Dir(), c.Strike, c.Expiration, iv, e})
+		}
+	}
+	sort.SliceStable(r.Legs, func(i, j int) bool { return r.Legs[i].Strike < r.Legs[j].Strike })
+	r.Skew = skew(r.Legs)
+	return r
+}
+
+func skew(legs []LegIV) (s Skew) {
+	var np, nc, n float64
+	var sk, sv, skk, skv float64
+	for _, l := range legs {
+		if l.Err != nil {
+			continue
+		}
+		if l.Call {
+			s.CallIV += l.IV
+			nc++
+		} else {
+			s.PutIV += l.IV
+			np++
+		}
+		if n == 0 || l.IV < s.Min {
+			s.Min = l.IV
+		}
+		if n == 0 || l.IV > s.Max {
+			s.Max = l.IV
+		}
+		n++
+		sk, sv, skk, skv = sk+l.Strike, sv+l.IV, skk+l.Strike*l.Strike, skv+l.Strike*l.IV
+	}
+
+	if np > 0 {
+		s.PutIV /= np
+	}
+	if nc > 0 {
+		s.CallIV /= nc
+	}
+	if np > 0 && nc > 0 {
+		s.Skew = s.PutIV - s.CallIV
+	}
+	if d := n*skk - sk*sk; n > 1 && d != 0 {
+		s.Slope = (n*skv - sk*sv) / d
+	}
+	return s
+}
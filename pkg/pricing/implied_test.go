@@ -0,0 +1,100 @@
+package pricing
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestImpliedVol(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	// Spots far from the generated strikes exercise deep in and out of the money options.
+	spot := gen.Float64Range(data.MinStrike/2, 2*data.MaxStrike)
+
+	ps.Property("PutIV reprices the recorded price", prop.ForAll(
+		func(p data.Put, in Inputs) bool {
+			p.Price = Put(p, in).Value
+			iv, e := PutIV(p, in)
+			if e != nil {
+				return false
+			}
+			in.Vol = iv
+			return math.Abs(Put(p, in).Value-p.Price) < 1e-6
+		},
+		data.GenLongPut(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("CallIV reprices the recorded price", prop.ForAll(
+		func(c data.Call, in Inputs) bool {
+			c.Price = Call(c, in).Value
+			iv, e := CallIV(c, in)
+			if e != nil {
+				return false
+			}
+			in.Vol = iv
+			return math.Abs(Call(c, in).Value-c.Price) < 1e-6
+		},
+		data.GenLongCall(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("CallIV recovers the pricing volatility near the money", prop.ForAll(
+		func(c data.Call, in Inputs) bool {
+			in.Spot = c.Strike
+			c.Price = -Call(c, in).Value
+			iv, e := CallIV(c, in)
+			return e == nil && math.Abs(iv-in.Vol) < 1e-6
+		},
+		data.GenShortCall(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Intrinsic-only prices imply zero volatility", prop.ForAll(
+		func(p data.Put, in Inputs) bool {
+			in.Vol = 0
+			in.Spot = p.Strike / 2
+			p.Price = Put(p, in).Value
+			iv, e := PutIV(p, in)
+			return e == nil && iv == 0
+		},
+		data.GenLongPut(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Prices outside no-arbitrage bounds are rejected", prop.ForAll(
+		func(c data.Call, in Inputs) bool {
+			in.Spot = 2 * c.Strike
+			c.Price = c.Strike / 2
+			_, below := CallIV(c, in)
+			c.Price = in.Spot + 1
+			_, above := CallIV(c, in)
+			return below == ErrBelowIntrinsic && above == ErrAboveBound
+		},
+		data.GenLongCall(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("StrategyIV reports every leg and a flat skew at one volatility", prop.ForAll(
+		func(s data.Strategy) bool {
+			m := Market{Spot: 500, Vol: 0.3, Rate: 0.02, Now: data.Expiration.AddDate(0, 0, -45)}
+			s.Sp[0].Strike, s.Sc[0].Strike = 0.95*m.Spot, 1.05*m.Spot
+			for i, p := range s.Sp {
+				s.Sp[i].Price = -Put(p, m.Inputs(p.Expiration)).Value
+			}
+			for i, c := range s.Sc {
+				s.Sc[i].Price = -Call(c, m.Inputs(c.Expiration)).Value
+			}
+			r := StrategyIV(&s, m)
+			if len(r.Legs) != s.CountOptions() {
+				return false
+			}
+			return math.Abs(r.Skew.Skew) < 1e-6 && math.Abs(r.Skew.Max-r.Skew.Min) < 1e-6 &&
+				math.Abs(r.Skew.PutIV-0.3) < 1e-6
+		},
+		data.GenShortStrangleStrategy(data.GenTicker())))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
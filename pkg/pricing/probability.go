@@ -0,0 +1,192 @@
+package pricing
+
+import (
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"sort"
+)
+
+// Probability that the underlying trades at a short strike at some point before expiration.
+type Touch struct {
+	Call   bool
+	Strike float64
+	Prob   float64
+}
+
+// Outcome probabilities and expected value of a strategy held to expiration.
+type Odds struct {
+	ProfitProb    float64 // Probability the expiration payoff is positive.
+	MaxProfitProb float64 // Probability the expiration payoff reaches its maximum. Zero when unbounded.
+	Touch         []Touch // One entry per distinct short strike.
+	ExpectedValue float64
+}
+
+// Converts days until expiration to the years used by Inputs.T.
+func DaysToYears(n float64) float64 {
+	return n / DaysPerYear
+}
+
+// Evaluates a strategy's expiration payoff under a lognormal distribution of the underlying with
+// volatility in.Vol and drift in.Rate - in.Dividend over in.T years. The payoff is linear between
+// strikes so every integral is computed exactly.
+func Outlook(s *data.Strategy, in Inputs) (o Odds) {
+	d := lognormal(in)
+
+	ks := append([]float64{0}, s.Strikes()...)
+	for _, b := range s.Breakevens() {
+		ks = append(ks, b)
+	}
+	ks = distinct(ks)
+
+	last := ks[len(ks)-1]
+	tail := s.PayoffAt(last+1) - s.PayoffAt(last)
+
+	// Integrate segment by segment, including the unbounded segment above the last point.
+	for i := range ks {
+		a, b := ks[i], math.Inf(1)
+		if i+1 < len(ks) {
+			b = ks[i+1]
+		}
+
+		va := s.PayoffAt(a)
+		slope := tail
+		mid := va + tail
+		if !math.IsInf(b, 1) {
+			slope = (s.PayoffAt(b) - va) / (b - a)
+			mid = s.PayoffAt((a + b) / 2)
+		}
+
+		mass := d.prob(a, b)
+		o.ExpectedValue += (va-slope*a)*mass + slope*d.partial(a, b)
+		if mid > 0 {
+			o.ProfitProb += mass
+		}
+	}
+
+	if p, unbounded := s.MaxProfit(); !unbounded {
+		top := func(u float64) bool {
+			return math.Abs(s.PayoffAt(u)-p) <= 1e-9*math.Max(1, math.Abs(p))
+		}
+		for i := range ks {
+			flat := i+1 < len(ks) && top(ks[i+1])
+			if i+1 == len(ks) {
+				flat = math.Abs(tail) <= 1e-9
+			}
+			if top(ks[i]) && flat {
+				b := math.Inf(1)
+				if i+1 < len(ks) {
+					b = ks[i+1]
+				}
+				o.MaxProfitProb += d.prob(ks[i], b)
+			}
+		}
+	}
+
+	for _, k := range distinct(putStrikes(s.Sp)) {
+		o.Touch = append(o.Touch, Touch{false, k, d.touch(k)})
+	}
+	for _, k := range distinct(callStrikes(s.Sc)) {
+		o.Touch = append(o.Touch, Touch{true, k, d.touch(k)})
+	}
+	return o
+}
+
+type distribution struct {
+	spot  float64
+	drift float64 // Drift of log price per year.
+	sd    float64 // Standard deviation of log price at expiration.
+	t     float64
+	vol   float64
+}
+
+func lognormal(in Inputs) distribution {
+	return distribution{
+		spot:  in.Spot,
+		drift: in.Rate - in.Dividend - in.Vol*in.Vol/2,
+		sd:    in.Vol * math.Sqrt(math.Max(in.T, 0)),
+		t:     math.Max(in.T, 0),
+		vol:   in.Vol,
+	}
+}
+
+// Standardised distance of log(x) from the mean log price, shifted by h standard deviations.
+func (d distribution) z(x, h float64) float64 {
+	if x <= 0 {
+		return math.Inf(-1)
+	} else if math.IsInf(x, 1) {
+		return math.Inf(1)
+	}
+	return (math.Log(x/d.spot)-d.drift*d.t)/d.sd - h
+}
+
+// Probability the underlying finishes in [a, b).
+func (d distribution) prob(a, b float64) float64 {
+	if d.sd == 0 {
+		f := d.forward()
+		if a <= f && f < b {
+			return 1
+		}
+		return 0
+	}
+	return cdf(d.z(b, 0)) - cdf(d.z(a, 0))
+}
+
+// Expected value of the underlying restricted to finishing in [a, b).
+func (d distribution) partial(a, b float64) float64 {
+	f := d.forward()
+	if d.sd == 0 {
+		return f * d.prob(a, b)
+	}
+	return f * (cdf(d.z(b, d.sd)) - cdf(d.z(a, d.sd)))
+}
+
+func (d distribution) forward() float64 {
+	return d.spot * math.Exp((d.drift)*d.t+d.sd*d.sd/2)
+}
+
+// Probability that the underlying reaches level h before expiration under geometric Brownian motion.
+func (d distribution) touch(h float64) float64 {
+	if h == d.spot {
+		return 1
+	} else if d.sd == 0 {
+		f := d.forward()
+		if (h > d.spot && f >= h) || (h < d.spot && f <= h) {
+			return 1
+		}
+		return 0
+	}
+
+	x := math.Log(h / d.spot)
+	nu := d.drift * d.t
+	reflect := math.Pow(h/d.spot, 2*d.drift/(d.vol*d.vol))
+	if h > d.spot {
+		return cdf((-x+nu)/d.sd) + reflect*cdf((-x-nu)/d.sd)
+	}
+	return cdf((x-nu)/d.sd) + reflect*cdf((x+nu)/d.sd)
+}
+
+func putStrikes(ps data.Puts) (ks []float64) {
+	for _, p := range ps {
+		ks = append(ks, p.Strike)
+	}
+	return ks
+}
+
+func callStrikes(cs data.Calls) (ks []float64) {
+	for _, c := range cs {
+		ks = append(ks, c.Strike)
+	}
+	return ks
+}
+
+// Sorted values with duplicates removed.
+func distinct(xs []float64) (ds []float64) {
+	ys := append([]float64(nil), xs...)
+	sort.Float64s(ys)
+	for i, x := range ys {
+		if i == 0 || x != ys[i-1] {
+			ds = append(ds, x)
+		}
+	}
+	return ds
+}
@@ -0,0 +1,79 @@
+package pricing
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestOutlook(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+
+	spot := gen.Float64Range(data.MinStrike, data.MaxStrike)
+
+	ps.Property("Long call expected value is its forward Black-Scholes value less premium", prop.ForAll(
+		func(s data.Strategy, in Inputs) bool {
+			c := s.Lc[0]
+			want := 100 * (Call(c, in).Value*math.Exp(in.Rate*in.T) - c.Price)
+			return math.Abs(Outlook(&s, in).ExpectedValue-want) < 1e-6*math.Max(1, math.Abs(want))
+		},
+		data.GenLongNakedCallStrategy(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Short put profits when finishing above its breakeven", prop.ForAll(
+		func(s data.Strategy, in Inputs) bool {
+			b := s.Breakevens()[0]
+			d2 := (math.Log(in.Spot/b) + (in.Rate-in.Dividend-in.Vol*in.Vol/2)*in.T) / (in.Vol * math.Sqrt(in.T))
+			return math.Abs(Outlook(&s, in).ProfitProb-cdf(d2)) < 1e-9
+		},
+		data.GenShortNakedPutStrategy(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Short strangle keeps max profit between its strikes", prop.ForAll(
+		func(s data.Strategy, in Inputs) bool {
+			d := lognormal(in)
+			want := d.prob(s.Sp[0].Strike, s.Sc[0].Strike)
+			return math.Abs(Outlook(&s, in).MaxProfitProb-want) < 1e-9
+		},
+		data.GenShortStrangleStrategy(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Probabilities are bounded and touching is likelier than finishing beyond", prop.ForAll(
+		func(s data.Strategy, in Inputs) bool {
+			o := Outlook(&s, in)
+			if o.ProfitProb < 0 || o.ProfitProb > 1+1e-9 || o.MaxProfitProb > 1+1e-9 {
+				return false
+			}
+			if p, _ := s.MaxProfit(); p > 0 && o.MaxProfitProb > o.ProfitProb+1e-9 {
+				return false
+			}
+			d := lognormal(in)
+			for _, t := range o.Touch {
+				beyond := d.prob(0, t.Strike)
+				if t.Strike > in.Spot {
+					beyond = d.prob(t.Strike, math.Inf(1))
+				}
+				if t.Prob > 1+1e-9 || t.Prob < beyond-1e-9 {
+					return false
+				}
+			}
+			return true
+		},
+		data.GenStrategy(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Property("Every short strike has a touch probability", prop.ForAll(
+		func(s data.Strategy, in Inputs) bool {
+			return len(Outlook(&s, in).Touch) == 2
+		},
+		data.GenShortIronCondorStrategy(data.GenTicker()),
+		GenInputs(spot)))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,87 @@
+package tax
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Adjustment code Form 8949 uses for losses disallowed by wash sales.
+const WashSaleCode = "W"
+
+const dateLayout = "01/02/2006"
+
+// Column headings of the Form 8949 export.
+var Form8949Header = []string{
+	"Term", "Description", "Date Acquired", "Date Sold", "Proceeds", "Cost Basis", "Code", "Adjustment", "Gain or Loss"}
+
+// Returns the gains realized in a calendar year of the book's Location, in the order they were realized.
+func (b *Book) GainsIn(year int) []Gain {
+	var gs []Gain
+	for _, g := range b.gains {
+		if g.Sold.In(b.location()).Year() == year {
+			gs = append(gs, g)
+		}
+	}
+	return gs
+}
+
+// Describes the property sold, e.g. "100 sh AAA" or "2 AAA 01/17/2020 450.00 Put".
+func Description(i ledger.Instrument, quantity int) string {
+	if i.Kind == ledger.StockKind {
+		return fmt.Sprintf("%d sh %s", abs(quantity), i.Ticker)
+	}
+	return fmt.Sprintf("%d %s %s %.2f %s", abs(quantity), i.Ticker, i.Expiration.Format(dateLayout), i.Strike, i.Kind)
+}
+
+// Writes gains as CSV in the layout of Form 8949: short term gains and then long term gains, each in order of
+// sale and followed by a row of their totals as carried to Schedule D. Dates are written as the gains hold
+// them, which for gains of a Book is in its Location.
+func WriteForm8949(w io.Writer, gs []Gain) error {
+	c := csv.NewWriter(w)
+	if e := c.Write(Form8949Header); e != nil {
+		return e
+	}
+
+	sorted := append([]Gain(nil), gs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Sold.Before(sorted[j].Sold) })
+	for _, t := range []Term{ShortTerm, LongTerm} {
+		var proceeds, basis, adjustment, gain float64
+		for _, g := range sorted {
+			if g.Term != t {
+				continue
+			}
+			code := ""
+			if g.WashSale != 0 {
+				code = WashSaleCode
+			}
+			e := c.Write([]string{
+				t.String(),
+				Description(g.Instrument, g.Quantity),
+				g.Acquired.Format(dateLayout),
+				g.Sold.Format(dateLayout),
+				money(g.Proceeds),
+				money(g.Basis),
+				code,
+				money(g.WashSale),
+				money(g.Amount())})
+			if e != nil {
+				return e
+			}
+			proceeds, basis, adjustment, gain = proceeds+g.Proceeds, basis+g.Basis, adjustment+g.WashSale, gain+g.Amount()
+		}
+		e := c.Write([]string{t.String(), "Total", "", "", money(proceeds), money(basis), "", money(adjustment), money(gain)})
+		if e != nil {
+			return e
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+func money(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,113 @@
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestForm8949(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	fills := gen.SliceOfN(15, ledger.GenFill(ledger.GenAsset(gen.Const("AAA"))))
+
+	ps.Property("Gains in a year are those sold in it", prop.ForAll(
+		func(fs []ledger.Fill, price float64) bool {
+			b, e := Replay(flatten(fs, price), FIFO)
+			if e != nil {
+				return false
+			}
+			n := 0
+			for _, y := range []int{2019, 2020} {
+				for _, g := range b.GainsIn(y) {
+					if g.Sold.Year() != y {
+						return false
+					}
+					n++
+				}
+			}
+			return n == len(b.Gains())
+		},
+		fills, gen.Float64Range(0, data.MaxOptionPrice)))
+
+	ps.Property("Late sales on New Year's Eve belong to the year of the account's local date", prop.ForAll(
+		func(f ledger.Fill, minutes int) bool {
+			est := time.FixedZone("EST", -5*60*60)
+			b := NewBook(FIFO)
+			b.Location = est
+			f.Side, f.Time = data.Buy, time.Date(2020, time.June, 1, 10, 0, 0, 0, est)
+			s := f
+			s.Side, s.Time = data.Sell, time.Date(2020, time.December, 31, 19, minutes, 0, 0, est).UTC()
+			if b.Apply(f) != nil || b.Apply(s) != nil {
+				return false
+			}
+			var buf bytes.Buffer
+			if WriteForm8949(&buf, b.GainsIn(2020)) != nil {
+				return false
+			}
+			rows, e := csv.NewReader(&buf).ReadAll()
+			return e == nil && len(b.GainsIn(2021)) == 0 && len(rows) == 4 && rows[1][3] == "12/31/2020"
+		},
+		ledger.GenFill(ledger.GenAsset(gen.Const("AAA"))), gen.IntRange(0, 5*60-1)))
+
+	ps.Property("Export lists every gain with term totals", prop.ForAll(
+		func(fs []ledger.Fill, price float64, long bool) bool {
+			all := flatten(fs, price)
+			if long {
+				all[len(all)-1].Time = all[len(all)-1].Time.AddDate(2, 0, 0)
+			}
+			b, e := Replay(all, FIFO)
+			if e != nil {
+				return false
+			}
+			var buf bytes.Buffer
+			if WriteForm8949(&buf, b.Gains()) != nil {
+				return false
+			}
+			rows, e := csv.NewReader(&buf).ReadAll()
+			if e != nil || len(rows) != len(b.Gains())+3 {
+				return false
+			}
+
+			short, lng := b.Realized()
+			totals := map[string]float64{ShortTerm.String(): short, LongTerm.String(): lng}
+			for _, r := range rows[1:] {
+				if len(r) != len(Form8949Header) {
+					return false
+				}
+				gain, _ := strconv.ParseFloat(r[8], 64)
+				adj, _ := strconv.ParseFloat(r[7], 64)
+				if r[1] == "Total" {
+					if math.Abs(gain-totals[r[0]]) > 0.01*float64(len(rows)) {
+						return false
+					}
+					continue
+				}
+				if (r[6] == WashSaleCode) != (adj != 0) {
+					return false
+				}
+			}
+			return rows[len(rows)-1][0] == LongTerm.String() && rows[len(rows)-1][1] == "Total"
+		},
+		fills, gen.Float64Range(0, data.MaxOptionPrice), gen.Bool()))
+
+	ps.Property("Descriptions name the instrument", prop.ForAll(
+		func(st data.Stock) bool {
+			s, _ := ledger.InstrumentOf(st)
+			i, _ := ledger.InstrumentOf(data.Put{Underlying: st, Strike: 450, Expiration: data.Expiration})
+			return Description(s, -100) == "100 sh AAA" && Description(i, 2) == "2 AAA 01/17/2020 450.00 Put"
+		},
+		data.GenStock(gen.Const("AAA"))))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,349 @@
+package tax
+
+import (
+	"errors"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"slices"
+	"sort"
+	"time"
+)
+
+var (
+	ErrNoLotsSelected = errors.New("specific identification requires the lots to close")
+	ErrUnknownLot     = errors.New("selected lot is not open against the fill")
+	ErrDuplicateLot   = errors.New("selected lot is named more than once")
+	ErrLotsShort      = errors.New("selected lots hold less than the fill closes")
+)
+
+// How the lots closed by a fill are chosen.
+type Method int
+
+const (
+	FIFO        Method = iota // Oldest lot first.
+	LIFO        Method = iota // Newest lot first.
+	HighestCost Method = iota // Lot with the highest cost per share first, or for short lots the lowest proceeds.
+	SpecificID  Method = iota // Lots named when closing.
+)
+
+func (m Method) String() string {
+	return []string{"FIFO", "LIFO", "HighestCost", "SpecificID"}[m]
+}
+
+// Holding period of a realized gain.
+type Term int
+
+const (
+	ShortTerm Term = iota
+	LongTerm  Term = iota
+)
+
+func (t Term) String() string {
+	return []string{"Short", "Long"}[t]
+}
+
+// Quantity of an instrument opened by one fill and not yet closed.
+type Lot struct {
+	ID         int
+	Instrument ledger.Instrument
+	Quantity   int       // Signed: negative for short lots.
+	Price      float64   // Opening price per share.
+	Fees       float64   // Opening fees of the open quantity.
+	Acquired   time.Time // Start of the holding period, moved back when the lot replaces shares sold in a wash sale.
+	Adjustment float64   // Losses disallowed by wash sales: added to the basis, or taken from short proceeds.
+	Washed     bool      // Replaced units closed in a wash sale and cannot replace any others.
+}
+
+// Cost of a long lot or net proceeds of a short lot, including opening fees and wash sale adjustments.
+func (l Lot) Basis() float64 {
+	v := l.Price * float64(abs(l.Quantity)) * l.Instrument.Multiplier
+	if l.Quantity < 0 {
+		return v - l.Fees - l.Adjustment
+	}
+	return v + l.Fees + l.Adjustment
+}
+
+// Removes n units from the lot and returns them as a lot of their own.
+func (l *Lot) take(n int) Lot {
+	part := *l
+	part.Fees = l.Fees * float64(n) / float64(abs(l.Quantity))
+	part.Adjustment = l.Adjustment * float64(n) / float64(abs(l.Quantity))
+	part.Quantity = n
+	if l.Quantity < 0 {
+		part.Quantity = -n
+	}
+	l.Fees -= part.Fees
+	l.Adjustment -= part.Adjustment
+	l.Quantity -= part.Quantity
+	return part
+}
+
+// Gain or loss realized by closing part or all of a lot.
+type Gain struct {
+	Lot        int
+	Instrument ledger.Instrument
+	Quantity   int // Signed: negative when a short lot was closed.
+	Acquired   time.Time
+	Sold       time.Time
+	Proceeds   float64 // Amount received, net of fees.
+	Basis      float64 // Amount paid, including fees.
+	WashSale   float64 // Part of the loss disallowed by a wash sale.
+	Term       Term
+}
+
+// Proceeds less basis plus any loss disallowed by a wash sale.
+func (g Gain) Amount() float64 {
+	return g.Proceeds - g.Basis + g.WashSale
+}
+
+// Open lots and realized gains of a series of fills.
+type Book struct {
+	Method Method
+	// Decides whether opening one instrument replaces another closed at a loss. Defaults to
+	// SubstantiallyIdentical when nil; SameUnderlying is the stricter reading.
+	Identical func(a, b ledger.Instrument) bool
+	// Time zone of the account. Lots and gains are dated, and gains assigned to tax years, by their calendar
+	// dates in it. Set it before applying fills. Defaults to UTC when nil.
+	Location *time.Location
+	lots     []Lot
+	gains    []Gain
+	pending  []pending
+	next     int
+}
+
+// Creates an empty book that closes lots by the method. A zero Book with its Method set is ready to use too.
+func NewBook(m Method) *Book {
+	return &Book{Method: m, Identical: SubstantiallyIdentical, Location: time.UTC}
+}
+
+// Replays fills in time order into a new book. Specific identification is not possible without naming lots,
+// so SpecificID books must be built with Close.
+func Replay(fs []ledger.Fill, m Method) (*Book, error) {
+	if m == SpecificID {
+		return nil, ErrNoLotsSelected
+	}
+	sorted := append([]ledger.Fill(nil), fs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	b := NewBook(m)
+	for _, f := range sorted {
+		if e := b.Apply(f); e != nil {
+			return nil, e
+		}
+	}
+	return b, nil
+}
+
+// Applies a fill, closing open lots on the opposite side chosen by the book's method and opening a new lot
+// with any remaining quantity.
+func (b *Book) Apply(f ledger.Fill) error {
+	if b.Method == SpecificID {
+		return ErrNoLotsSelected
+	}
+	i, e := ledger.InstrumentOf(f.Asset)
+	if e != nil {
+		return e
+	}
+	return b.fill(i, f, b.candidates(i, f))
+}
+
+// Applies a fill, closing the lots with the given ids in order. The lots must be named once each and cover the
+// whole fill. Without ids the fill opens a new lot, which it may only do when no lot is open against it. Lots
+// may be named under any method.
+func (b *Book) Close(f ledger.Fill, ids ...int) error {
+	i, e := ledger.InstrumentOf(f.Asset)
+	if e != nil {
+		return e
+	}
+	if len(ids) == 0 {
+		if len(b.candidates(i, f)) > 0 {
+			return ErrNoLotsSelected
+		}
+		return b.fill(i, f, nil)
+	}
+
+	var idx []int
+	held := 0
+	for _, id := range ids {
+		k := b.find(id)
+		if k < 0 || b.lots[k].Instrument != i || (b.lots[k].Quantity > 0) == (f.Side == data.Buy) {
+			return ErrUnknownLot
+		}
+		if slices.Contains(idx, k) {
+			return ErrDuplicateLot
+		}
+		idx = append(idx, k)
+		held += abs(b.lots[k].Quantity)
+	}
+	if held < f.Quantity {
+		return ErrLotsShort
+	}
+	return b.fill(i, f, idx)
+}
+
+// Returns the open lots in the order they were opened.
+func (b *Book) Lots() []Lot {
+	return append([]Lot(nil), b.lots...)
+}
+
+// Returns the realized gains in the order they were realized.
+func (b *Book) Gains() []Gain {
+	return append([]Gain(nil), b.gains...)
+}
+
+// Totals of the realized short and long term gains.
+func (b *Book) Realized() (short, long float64) {
+	for _, g := range b.gains {
+		if g.Term == LongTerm {
+			long += g.Amount()
+		} else {
+			short += g.Amount()
+		}
+	}
+	return short, long
+}
+
+// Indices of the lots that can be closed by the fill, in the order the method closes them.
+func (b *Book) candidates(i ledger.Instrument, f ledger.Fill) []int {
+	var idx []int
+	for k, l := range b.lots {
+		if l.Instrument == i && (l.Quantity > 0) != (f.Side == data.Buy) {
+			idx = append(idx, k)
+		}
+	}
+	switch b.Method {
+	case LIFO:
+		sort.SliceStable(idx, func(x, y int) bool { return b.lots[idx[x]].Acquired.After(b.lots[idx[y]].Acquired) })
+	case HighestCost:
+		sort.SliceStable(idx, func(x, y int) bool {
+			a, c := b.lots[idx[x]], b.lots[idx[y]]
+			ua, uc := a.Basis()/float64(abs(a.Quantity)), c.Basis()/float64(abs(c.Quantity))
+			if a.Quantity < 0 {
+				return ua < uc
+			}
+			return ua > uc
+		})
+	default:
+		sort.SliceStable(idx, func(x, y int) bool { return b.lots[idx[x]].Acquired.Before(b.lots[idx[y]].Acquired) })
+	}
+	return idx
+}
+
+// Closes the fill against the lots at the indices in order and opens a lot with the remainder.
+func (b *Book) fill(i ledger.Instrument, f ledger.Fill, idx []int) error {
+	if f.Quantity <= 0 || (f.Side != data.Buy && f.Side != data.Sell) {
+		return errors.New("fill must have a side and a positive quantity")
+	}
+	fee := f.Fees / float64(f.Quantity)
+	at := f.Time.In(b.location())
+	left := f.Quantity
+	first := len(b.gains)
+	for _, k := range idx {
+		if left == 0 {
+			break
+		}
+		l := &b.lots[k]
+		n := min(left, abs(l.Quantity))
+		if n == 0 {
+			continue
+		}
+		b.gains = append(b.gains, realize(l.take(n), f.Price, fee*float64(n), at))
+		left -= n
+	}
+
+	open := b.lots[:0]
+	for _, l := range b.lots {
+		if l.Quantity != 0 {
+			open = append(open, l)
+		}
+	}
+	b.lots = open
+
+	if left > 0 {
+		q := left
+		if f.Side == data.Sell {
+			q = -left
+		}
+		b.lots = append(b.lots, Lot{
+			ID:         b.id(),
+			Instrument: i,
+			Quantity:   q,
+			Price:      f.Price,
+			Fees:       fee * float64(left),
+			Acquired:   at})
+		b.replaceAfter(len(b.lots) - 1)
+	}
+	for k := first; k < len(b.gains); k++ {
+		b.wash(k)
+	}
+	return nil
+}
+
+// Gain of closing a lot at a price per share with the given closing fees. Short lots are always short term.
+func realize(l Lot, price, fees float64, at time.Time) Gain {
+	v := price * float64(abs(l.Quantity)) * l.Instrument.Multiplier
+	g := Gain{
+		Lot:        l.ID,
+		Instrument: l.Instrument,
+		Quantity:   l.Quantity,
+		Acquired:   l.Acquired,
+		Sold:       at,
+		Term:       term(l, at)}
+	if l.Quantity < 0 {
+		g.Proceeds, g.Basis = l.Basis(), v+fees
+	} else {
+		g.Proceeds, g.Basis = v-fees, l.Basis()
+	}
+	return g
+}
+
+// Long term when a long lot is held for more than a year: sold after the anniversary of the day it was
+// acquired. Only the calendar dates count, so a sale on the anniversary is short term at any time of day.
+func term(l Lot, sold time.Time) Term {
+	if l.Quantity > 0 && date(sold).After(date(l.Acquired).AddDate(1, 0, 0)) {
+		return LongTerm
+	}
+	return ShortTerm
+}
+
+// Midnight UTC of the calendar date of t in its own location.
+func date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (b *Book) location() *time.Location {
+	if b.Location == nil {
+		return time.UTC
+	}
+	return b.Location
+}
+
+func (b *Book) identical(x, y ledger.Instrument) bool {
+	if b.Identical == nil {
+		return SubstantiallyIdentical(x, y)
+	}
+	return b.Identical(x, y)
+}
+
+// Returns the next lot id. Ids start at 1.
+func (b *Book) id() int {
+	b.next++
+	return b.next
+}
+
+func (b *Book) find(id int) int {
+	for k, l := range b.lots {
+		if l.ID == id {
+			return k
+		}
+	}
+	return -1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
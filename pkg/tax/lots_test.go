@@ -0,0 +1,199 @@
+package tax
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// Appends fills that flatten every instrument at a price per share a month after the last fill.
+func flatten(fs []ledger.Fill, price float64) []ledger.Fill {
+	net := make(map[ledger.Instrument]int)
+	assets := make(map[ledger.Instrument]data.Asset)
+	var order []ledger.Instrument
+	for _, f := range fs {
+		i, _ := ledger.InstrumentOf(f.Asset)
+		if _, ok := assets[i]; !ok {
+			order = append(order, i)
+		}
+		assets[i] = f.Asset
+		if f.Side == data.Buy {
+			net[i] += f.Quantity
+		} else {
+			net[i] -= f.Quantity
+		}
+	}
+	out := append([]ledger.Fill(nil), fs...)
+	for _, i := range order {
+		f := ledger.Fill{Time: ledger.Epoch.AddDate(0, 2, 0), Asset: assets[i], Side: data.Sell, Quantity: net[i], Price: price}
+		if net[i] < 0 {
+			f.Side, f.Quantity = data.Buy, -net[i]
+		}
+		if f.Quantity > 0 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Cash received less cash paid over the fills.
+func cash(fs []ledger.Fill) (v float64) {
+	for _, f := range fs {
+		i, _ := ledger.InstrumentOf(f.Asset)
+		x := f.Price * float64(f.Quantity) * i.Multiplier
+		if f.Side == data.Buy {
+			x = -x
+		}
+		v += x - f.Fees
+	}
+	return v
+}
+
+func TestLots(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	fills := gen.SliceOfN(15, ledger.GenFill(ledger.GenAsset(gen.Const("AAA"))))
+	methods := gen.OneConstOf(FIFO, LIFO, HighestCost)
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6*math.Max(1, math.Abs(a)) }
+
+	ps.Property("Open lots net the fills", prop.ForAll(
+		func(fs []ledger.Fill, m Method) bool {
+			b, e := Replay(fs, m)
+			if e != nil {
+				return false
+			}
+			net := make(map[ledger.Instrument]int)
+			for _, f := range fs {
+				i, _ := ledger.InstrumentOf(f.Asset)
+				if f.Side == data.Buy {
+					net[i] += f.Quantity
+				} else {
+					net[i] -= f.Quantity
+				}
+			}
+			for _, l := range b.Lots() {
+				if l.Quantity == 0 || (net[l.Instrument] > 0) != (l.Quantity > 0) {
+					return false
+				}
+				net[l.Instrument] -= l.Quantity
+			}
+			for _, n := range net {
+				if n != 0 {
+					return false
+				}
+			}
+			return true
+		},
+		fills, methods))
+
+	ps.Property("Flat books realize the net cash under every method", prop.ForAll(
+		func(fs []ledger.Fill, m Method, price float64) bool {
+			all := flatten(fs, price)
+			b, e := Replay(all, m)
+			if e != nil || len(b.Lots()) != 0 {
+				return false
+			}
+			short, long := b.Realized()
+			return long == 0 && near(short, cash(all))
+		},
+		fills, methods, gen.Float64Range(0, data.MaxOptionPrice)))
+
+	ps.Property("Lots held over a year are long term", prop.ForAll(
+		func(f ledger.Fill, days int) bool {
+			f.Side = data.Buy
+			s := f
+			s.Side, s.Time = data.Sell, f.Time.AddDate(0, 0, days)
+			b, e := Replay([]ledger.Fill{f, s}, FIFO)
+			if e != nil {
+				return false
+			}
+			year := int(f.Time.AddDate(1, 0, 0).Sub(f.Time).Hours() / 24)
+			g := b.Gains()[0]
+			return len(b.Gains()) == 1 && (g.Term == LongTerm) == (days > year) && g.Quantity == f.Quantity
+		},
+		ledger.GenFill(ledger.GenAsset(gen.Const("AAA"))), gen.IntRange(300, 400)))
+
+	ps.Property("Sales on the anniversary are short term at any time of day", prop.ForAll(
+		func(f ledger.Fill, minutes int, late bool) bool {
+			f.Side, f.Time = data.Buy, time.Date(2020, time.March, 2, 10, 0, 0, 0, time.UTC)
+			s := f
+			s.Side, s.Time = data.Sell, time.Date(2021, time.March, 2, 0, minutes, 0, 0, time.UTC)
+			if late {
+				s.Time = s.Time.AddDate(0, 0, 1)
+			}
+			b, e := Replay([]ledger.Fill{f, s}, FIFO)
+			return e == nil && (b.Gains()[0].Term == LongTerm) == late
+		},
+		ledger.GenFill(ledger.GenAsset(gen.Const("AAA"))), gen.IntRange(0, 24*60-1), gen.Bool()))
+
+	ps.Property("Short lots are short term", prop.ForAll(
+		func(f ledger.Fill) bool {
+			f.Side = data.Sell
+			s := f
+			s.Side, s.Time = data.Buy, f.Time.AddDate(2, 0, 0)
+			b, e := Replay([]ledger.Fill{f, s}, FIFO)
+			return e == nil && b.Gains()[0].Term == ShortTerm && b.Gains()[0].Quantity == -f.Quantity
+		},
+		ledger.GenFill(ledger.GenAsset(gen.Const("AAA")))))
+
+	ps.Property("Methods pick the lot they name", prop.ForAll(
+		func(a, c ledger.Fill, pick int) bool {
+			lots := []ledger.Fill{a, c, a}
+			for k := range lots {
+				lots[k].Side, lots[k].Asset, lots[k].Quantity, lots[k].Fees = data.Buy, a.Asset, 1, 0
+				lots[k].Time = ledger.Epoch.AddDate(0, 0, k)
+			}
+			lots[2].Price = (a.Price + c.Price) / 2
+			sell := lots[0]
+			sell.Side, sell.Time = data.Sell, ledger.Epoch.AddDate(0, 1, 0)
+
+			closed := func(m Method) int {
+				b := NewBook(m)
+				for _, f := range lots {
+					b.Apply(f)
+				}
+				b.Apply(sell)
+				return b.Gains()[0].Lot
+			}
+			highest := 1
+			if c.Price > a.Price {
+				highest = 2
+			}
+			b := NewBook(SpecificID)
+			for _, f := range lots {
+				b.Close(f)
+			}
+			e := b.Close(sell, pick)
+			return closed(FIFO) == 1 && closed(LIFO) == 3 && closed(HighestCost) == highest &&
+				e == nil && b.Gains()[0].Lot == pick && b.Apply(sell) == ErrNoLotsSelected && b.Close(sell, 9) == ErrUnknownLot
+		},
+		ledger.GenFill(ledger.GenAsset(gen.Const("AAA"))), ledger.GenFill(ledger.GenAsset(gen.Const("AAA"))),
+		gen.IntRange(1, 3)))
+
+	ps.Property("Specific lots must be named once and cover the fill", prop.ForAll(
+		func(a ledger.Fill, n int) bool {
+			a.Side, a.Fees = data.Buy, 0
+			b := NewBook(SpecificID)
+			for k := 0; k < 2; k++ {
+				if b.Close(a) != nil {
+					return false
+				}
+				a.Time = a.Time.Add(time.Hour)
+			}
+			sell := a
+			sell.Side, sell.Quantity = data.Sell, a.Quantity+n
+			return b.Close(sell, 1, 1) == ErrDuplicateLot && b.Close(sell, 1) == ErrLotsShort &&
+				b.Close(sell) == ErrNoLotsSelected && len(b.Gains()) == 0 &&
+				(n > a.Quantity || b.Close(sell, 1, 2) == nil && len(b.Lots()) == min(1, a.Quantity-n))
+		},
+		ledger.GenFill(ledger.GenAsset(gen.Const("AAA"))), gen.IntRange(1, 10)))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}
@@ -0,0 +1,103 @@
+package tax
+
+import (
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"math"
+	"slices"
+)
+
+// Days before and after closing a lot at a loss within which opening a substantially identical one on the same
+// side makes it a wash sale.
+const WashDays = 30
+
+// True when the instruments are the stock of one ticker, or options on it of the same kind, strike,
+// expiration and multiplier.
+func SubstantiallyIdentical(a, b ledger.Instrument) bool {
+	return a == b
+}
+
+// True when the instruments are on the same underlying ticker and are both stock, both options of the same
+// kind whatever their strikes and expirations, or stock and a call, which is a contract to acquire the stock.
+// Disallows more losses than SubstantiallyIdentical.
+func SameUnderlying(a, b ledger.Instrument) bool {
+	if a.Ticker != b.Ticker {
+		return false
+	}
+	if a.Kind == b.Kind {
+		return true
+	}
+	stockOrCall := func(k ledger.Kind) bool { return k == ledger.StockKind || k == ledger.CallKind }
+	return stockOrCall(a.Kind) && stockOrCall(b.Kind)
+}
+
+// A loss whose units are not yet all replaced, counted in shares.
+type pending struct {
+	gain   int
+	shares float64
+}
+
+// Disallows the loss of a gain from closing a lot against identical lots opened on the same side within
+// WashDays before the close: long lots bought, or short lots, such as short options, sold. Units not replaced
+// stay pending until WashDays after the close.
+func (b *Book) wash(k int) {
+	g := b.gains[k]
+	if g.Quantity == 0 || g.Amount() >= 0 {
+		return
+	}
+	p := pending{gain: k, shares: float64(abs(g.Quantity)) * g.Instrument.Multiplier}
+	from := g.Sold.AddDate(0, 0, -WashDays)
+	for j := 0; j < len(b.lots) && p.shares > 0; j++ {
+		l := b.lots[j]
+		if (l.Quantity > 0) == (g.Quantity > 0) && !l.Washed && l.ID != g.Lot && !l.Acquired.Before(from) && !l.Acquired.After(g.Sold) &&
+			b.identical(g.Instrument, l.Instrument) {
+			p.shares -= b.replace(&p, j)
+		}
+	}
+	if p.shares > 0 {
+		b.pending = append(b.pending, p)
+	}
+}
+
+// Applies pending losses to a lot just opened at index j on their side, dropping losses whose window has passed.
+func (b *Book) replaceAfter(j int) {
+	var left []pending
+	for _, p := range b.pending {
+		g := b.gains[p.gain]
+		if b.lots[j].Acquired.After(g.Sold.AddDate(0, 0, WashDays)) {
+			continue
+		}
+		l := b.lots[j]
+		if (l.Quantity > 0) == (g.Quantity > 0) && !l.Washed && b.identical(g.Instrument, l.Instrument) {
+			p.shares -= b.replace(&p, j)
+		}
+		if p.shares > 0 {
+			left = append(left, p)
+		}
+	}
+	b.pending = left
+}
+
+// Moves the loss on up to the pending shares onto the lot at index j, splitting off the units of the lot that
+// replace them. The replacement's holding period is extended by that of the lot sold. Returns the shares
+// replaced.
+func (b *Book) replace(p *pending, j int) float64 {
+	g := &b.gains[p.gain]
+	l := &b.lots[j]
+	m := l.Instrument.Multiplier
+	units := min(abs(l.Quantity), int(math.Ceil(p.shares/m-1e-9)))
+	if units < abs(l.Quantity) {
+		rest := l.take(abs(l.Quantity) - units)
+		rest.ID = b.id()
+		b.lots = slices.Insert(b.lots, j+1, rest)
+		l = &b.lots[j]
+	}
+
+	sold := float64(abs(g.Quantity)) * g.Instrument.Multiplier
+	covered := math.Min(p.shares, float64(units)*m)
+	disallowed := (g.Basis - g.Proceeds) * covered / sold
+	g.WashSale += disallowed
+	l.Adjustment += disallowed
+	l.Acquired = l.Acquired.Add(-g.Sold.Sub(g.Acquired))
+	l.Washed = true
+	return covered
+}
@@ -0,0 +1,194 @@
+package tax
+
+import (
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/osheari1/TradeTrack/pkg/data"
+	"github.com/osheari1/TradeTrack/pkg/ledger"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Fill of n units of the asset at a price, days after Epoch.
+func fillOf(a data.Asset, side data.Side, n int, price float64, days int) ledger.Fill {
+	return ledger.Fill{Time: ledger.Epoch.AddDate(0, 0, days), Asset: a, Side: side, Quantity: n, Price: price}
+}
+
+// Replays fills in a FIFO book that treats instruments as identical when identical says so.
+func replayWith(fs []ledger.Fill, identical func(a, b ledger.Instrument) bool) (*Book, error) {
+	b := NewBook(FIFO)
+	b.Identical = identical
+	for _, f := range fs {
+		if e := b.Apply(f); e != nil {
+			return nil, e
+		}
+	}
+	return b, nil
+}
+
+func TestWashSales(t *testing.T) {
+
+	params := gopter.DefaultTestParametersWithSeed(42)
+	ps := gopter.NewProperties(params)
+	stock := data.GenStock(gen.Const("AAA"))
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6*math.Max(1, math.Abs(a)) }
+
+	ps.Property("Buying within the window after a loss washes it", prop.ForAll(
+		func(st data.Stock, price, drop float64, days int) bool {
+			b, e := Replay([]ledger.Fill{
+				fillOf(st, data.Buy, 10, price, 0),
+				fillOf(st, data.Sell, 10, price-drop, 40),
+				fillOf(st, data.Buy, 10, price, 40+days)}, FIFO)
+			if e != nil {
+				return false
+			}
+			g, l := b.Gains()[0], b.Lots()[0]
+			loss := drop * 10
+			if days > WashDays {
+				return g.WashSale == 0 && near(g.Amount(), -loss) && l.Adjustment == 0 && !l.Washed
+			}
+			return near(g.WashSale, loss) && near(g.Amount(), 0) && near(l.Adjustment, loss) && l.Washed &&
+				l.Acquired.Equal(ledger.Epoch.AddDate(0, 0, days))
+		},
+		stock, gen.Float64Range(50, 100), gen.Float64Range(1, 10), gen.IntRange(0, 60)))
+
+	ps.Property("Buying within the window before a loss washes it", prop.ForAll(
+		func(st data.Stock, price, drop float64, days int) bool {
+			b, e := Replay([]ledger.Fill{
+				fillOf(st, data.Buy, 10, price, 0),
+				fillOf(st, data.Buy, 10, price, 40-days),
+				fillOf(st, data.Sell, 10, price-drop, 40)}, FIFO)
+			if e != nil {
+				return false
+			}
+			g, l := b.Gains()[0], b.Lots()[0]
+			return (g.WashSale > 0) == (days <= WashDays) && (days > WashDays || near(l.Adjustment, drop*10))
+		},
+		stock, gen.Float64Range(50, 100), gen.Float64Range(1, 10), gen.IntRange(1, 39)))
+
+	ps.Property("Gains are never washed", prop.ForAll(
+		func(st data.Stock, price, rise float64, days int) bool {
+			b, e := Replay([]ledger.Fill{
+				fillOf(st, data.Buy, 10, price, 0),
+				fillOf(st, data.Sell, 10, price+rise, 40),
+				fillOf(st, data.Buy, 10, price, 40+days)}, FIFO)
+			return e == nil && b.Gains()[0].WashSale == 0 && b.Lots()[0].Adjustment == 0
+		},
+		stock, gen.Float64Range(50, 100), gen.Float64Range(0, 10), gen.IntRange(0, 30)))
+
+	ps.Property("Partial replacement washes part of the loss", prop.ForAll(
+		func(st data.Stock, price, drop float64, n int) bool {
+			b, e := Replay([]ledger.Fill{
+				fillOf(st, data.Buy, 10, price, 0),
+				fillOf(st, data.Sell, 10, price-drop, 40),
+				fillOf(st, data.Buy, n, price, 45),
+				fillOf(st, data.Buy, 10, price, 50)}, FIFO)
+			if e != nil {
+				return false
+			}
+			g, ls := b.Gains()[0], b.Lots()
+			return near(g.WashSale, drop*10) && ls[0].Washed && near(ls[0].Adjustment, drop*float64(n)) &&
+				ls[1].Quantity == 10-n && near(ls[1].Adjustment, drop*float64(10-n)) && ls[2].Quantity == n && !ls[2].Washed
+		},
+		stock, gen.Float64Range(50, 100), gen.Float64Range(1, 10), gen.IntRange(1, 9)))
+
+	ps.Property("A call splits to replace only the shares sold", prop.ForAll(
+		func(st data.Stock, c data.Call, price, drop float64) bool {
+			b, e := replayWith([]ledger.Fill{
+				fillOf(st, data.Buy, 150, price, 0),
+				fillOf(st, data.Sell, 150, price-drop, 40),
+				fillOf(c, data.Buy, 3, 1, 41)}, SameUnderlying)
+			if e != nil {
+				return false
+			}
+			g, ls := b.Gains()[0], b.Lots()
+			return near(g.WashSale, drop*150) && len(ls) == 2 && ls[0].Quantity == 2 && ls[1].Quantity == 1 &&
+				ls[0].Washed && !ls[1].Washed && near(ls[0].Adjustment, drop*150)
+		},
+		stock, data.GenCall(gen.Const("AAA")), gen.Float64Range(50, 100), gen.Float64Range(1, 10)))
+
+	ps.Property("Buying back a short option at a loss and selling it again washes the loss", prop.ForAll(
+		func(p data.Put, price, rise float64, days int) bool {
+			b, e := Replay([]ledger.Fill{
+				fillOf(p, data.Sell, 2, price, 0),
+				fillOf(p, data.Buy, 2, price+rise, 40),
+				fillOf(p, data.Sell, 2, price, 40+days)}, FIFO)
+			if e != nil {
+				return false
+			}
+			g, l := b.Gains()[0], b.Lots()[0]
+			loss := rise * 2 * data.ContractMultiplier
+			if days > WashDays {
+				return g.WashSale == 0 && near(g.Amount(), -loss) && l.Adjustment == 0 && !l.Washed
+			}
+			return near(g.WashSale, loss) && near(g.Amount(), 0) && l.Quantity == -2 && l.Washed &&
+				near(l.Basis(), price*2*data.ContractMultiplier-loss)
+		},
+		data.GenPut(gen.Const("AAA")), gen.Float64Range(1, 5), gen.Float64Range(0.5, 5), gen.IntRange(0, 60)))
+
+	ps.Property("Buying options on a closed short loss does not wash it", prop.ForAll(
+		func(p data.Put, price, rise float64) bool {
+			b, e := Replay([]ledger.Fill{
+				fillOf(p, data.Sell, 2, price, 0),
+				fillOf(p, data.Buy, 2, price+rise, 40),
+				fillOf(p, data.Buy, 2, price, 41)}, FIFO)
+			return e == nil && b.Gains()[0].WashSale == 0 && !b.Lots()[0].Washed
+		},
+		data.GenPut(gen.Const("AAA")), gen.Float64Range(1, 5), gen.Float64Range(0.5, 5)))
+
+	ps.Property("Rolling a losing option to another strike or expiration is not a wash sale", prop.ForAll(
+		func(p data.Put, price, rise, strike float64, later bool) bool {
+			q := p
+			if later {
+				q.Expiration = q.Expiration.AddDate(0, 1, 0)
+			} else {
+				q.Strike += strike
+			}
+			b, e := Replay([]ledger.Fill{
+				fillOf(p, data.Sell, 1, price, 0),
+				fillOf(p, data.Buy, 1, price+rise, 40),
+				fillOf(q, data.Sell, 1, price, 40)}, FIFO)
+			pi, _ := ledger.InstrumentOf(p)
+			qi, _ := ledger.InstrumentOf(q)
+			return e == nil && b.Gains()[0].WashSale == 0 && !SubstantiallyIdentical(pi, qi) && SameUnderlying(pi, qi)
+		},
+		data.GenPut(gen.Const("AAA")), gen.Float64Range(1, 5), gen.Float64Range(0.5, 5), gen.Float64Range(1, 50),
+		gen.Bool()))
+
+	ps.Property("A zero Book washes as NewBook does", prop.ForAll(
+		func(st data.Stock, price, drop float64) bool {
+			fs := []ledger.Fill{
+				fillOf(st, data.Buy, 10, price, 0),
+				fillOf(st, data.Buy, 5, price, 20),
+				fillOf(st, data.Sell, 10, price-drop, 40)}
+			want, _ := replayWith(fs, SubstantiallyIdentical)
+			b := &Book{Method: FIFO}
+			for _, f := range fs {
+				if b.Apply(f) != nil {
+					return false
+				}
+			}
+			return reflect.DeepEqual(b.Gains(), want.Gains()) && reflect.DeepEqual(b.Lots(), want.Lots()) &&
+				b.Gains()[0].Lot == 1 && b.Lots()[0].ID == 2 && b.Lots()[0].Washed
+		},
+		stock, gen.Float64Range(50, 100), gen.Float64Range(1, 10)))
+
+	ps.Property("Substantially identical instruments", prop.ForAll(
+		func(st data.Stock, p data.Put, c data.Call) bool {
+			s, _ := ledger.InstrumentOf(st)
+			pi, _ := ledger.InstrumentOf(p)
+			ci, _ := ledger.InstrumentOf(c)
+			other := s
+			other.Ticker = "BBB"
+			return SubstantiallyIdentical(s, s) && SubstantiallyIdentical(pi, pi) && SubstantiallyIdentical(ci, ci) &&
+				!SubstantiallyIdentical(s, ci) && !SubstantiallyIdentical(ci, pi) && !SubstantiallyIdentical(s, other) &&
+				SameUnderlying(s, ci) && SameUnderlying(ci, s) && SameUnderlying(pi, pi) &&
+				!SameUnderlying(s, pi) && !SameUnderlying(ci, pi) && !SameUnderlying(s, other)
+		},
+		stock, data.GenPut(gen.Const("AAA")), data.GenCall(gen.Const("AAA"))))
+
+	ps.Run(gopter.NewFormatedReporter(true, 80, os.Stdout))
+}